@@ -0,0 +1,54 @@
+// Package bot has computer players for sixDivides. A bot looks at the state
+// and picks the next move for the current player, one action at a time, so
+// the client can show each action before asking for the next.
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"sixDivides/rules"
+)
+
+// Bot picks moves for whichever player's turn it is. A bot keeps state between
+// moves, so it must only be asked for one move at a time, but that can be from
+// any goroutine.
+type Bot interface {
+	// Name is the kind of bot, as passed to New
+	Name() string
+	// NextMove returns the next action for the current player of s. It returns
+	// rules.EndTurnMove when there is nothing worth doing.
+	NextMove(s rules.State) rules.Move
+}
+
+// Kinds are the names New accepts, weakest first
+var Kinds = []string{"random", "greedy", "minimax-easy", "minimax-normal", "minimax-hard", "mcts-easy", "mcts-normal", "mcts-hard"}
+
+var ErrUnknownKind = errors.New("unknown bot")
+
+// New makes a bot of the named kind. The seed makes its choices repeatable.
+// A kind starting with ExternalPrefix runs the rest as a program, and the bot
+// has to be given to Close when it is done with.
+func New(kind string, seed int64) (Bot, error) {
+	if command, ok := strings.CutPrefix(kind, ExternalPrefix); ok {
+		return NewExternal(command)
+	}
+	switch kind {
+	case "random":
+		return NewRandom(seed), nil
+	case "greedy":
+		return NewGreedy(seed), nil
+	}
+	for _, difficulty := range Difficulties {
+		if kind == "minimax-"+difficulty.Name {
+			return NewMinimax(difficulty), nil
+		}
+	}
+	for _, difficulty := range MCTSDifficulties {
+		if kind == "mcts-"+difficulty.Name {
+			return NewMCTS(difficulty, seed), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKind, kind)
+}
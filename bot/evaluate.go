@@ -0,0 +1,74 @@
+package bot
+
+import "sixDivides/rules"
+
+// weights used by Evaluate, per point of piece value, action and so on
+const (
+	weightMaterial = 10
+	weightIncome   = 6
+	weightOutpost  = 15
+	weightThreat   = 8
+	weightApproach = 2
+	scoreWin       = 1_000_000
+)
+
+// Evaluate scores the position for the player, higher is better for them. It
+// is the players own standing less that of the strongest player still in the
+// game against them, so it treats every other player as one opponent.
+func Evaluate(s rules.State, player int) int {
+	if s.GameOver {
+		switch s.Winner {
+		case player:
+			return scoreWin
+		case rules.NoWinner:
+			return 0
+		default:
+			return -scoreWin
+		}
+	}
+	if s.Players[player].Eliminated {
+		return -scoreWin
+	}
+
+	strongest := 0
+	found := false
+	for i, other := range s.Players {
+		if i == player || other.Eliminated {
+			continue
+		}
+		score := standing(s, i)
+		if !found || score > strongest {
+			strongest = score
+			found = true
+		}
+	}
+	return standing(s, player) - strongest
+}
+
+// standing is how well placed the player is on their own: the value of their
+// pieces, the actions they make each turn, their outposts, and the enemy
+// pieces their soldiers could take next, or how far they have to go to reach
+// one. Actions kept for later are left out, otherwise ending the turn early
+// always looks better than playing on.
+func standing(s rules.State, player int) int {
+	p := s.Players[player]
+	score := weightIncome*rules.ActionsFor(p) - weightApproach*soldierDistance(s, player)
+
+	for _, piece := range p.Pieces {
+		score += weightMaterial * piece.Value
+		if piece.Value == 6 {
+			score += weightOutpost
+		}
+		if piece.Value != 2 && piece.Value != 4 {
+			continue
+		}
+		// a soldier threatens any neighbouring enemy piece it would remove
+		for _, d := range rules.Directions {
+			target, ok := s.PieceAt(rules.Position{X: piece.Position.X + d.X, Y: piece.Position.Y + d.Y})
+			if ok && target.PlayerIndex != player && target.Value <= piece.Value {
+				score += weightThreat * target.Value
+			}
+		}
+	}
+	return score
+}
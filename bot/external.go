@@ -0,0 +1,225 @@
+package bot
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"sixDivides/notation"
+	"sixDivides/rules"
+)
+
+// ExternalPrefix starts a bot kind that runs a program with the external bot
+// protocol, such as "exec:sixbot -kind minimax-hard"
+const ExternalPrefix = "exec:"
+
+// DefaultTimeLimit is how long an external bot has to answer
+const DefaultTimeLimit = 5 * time.Second
+
+// quitWait is how long a bot has to exit after quit before it is killed
+const quitWait = 2 * time.Second
+
+// the longest line a bot can send, positions are small so this is plenty
+const maxLine = 1 << 20
+
+var (
+	ErrNoCommand   = errors.New("external bot has no command")
+	ErrBotTimeout  = errors.New("external bot did not answer in time")
+	ErrBotExited   = errors.New("external bot exited")
+	ErrBotProtocol = errors.New("external bot broke the protocol")
+)
+
+// External is a bot running as its own program, see protocol.go for what it
+// is sent. It must be closed to stop the program.
+type External struct {
+	command string
+	name    string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	// messages has every line the bot sends except logs, and is closed when it exits
+	messages chan BotMessage
+	id       int
+	// TimeLimit is how long the bot has to answer each position
+	TimeLimit time.Duration
+	closeOnce sync.Once
+}
+
+// NewExternal starts the command, split on spaces, and waits for its hello
+func NewExternal(command string) (*External, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, ErrNoCommand
+	}
+
+	b := &External{command: command, name: command, messages: make(chan BotMessage, 8), TimeLimit: DefaultTimeLimit}
+	b.cmd = exec.Command(args[0], args[1:]...)
+	b.cmd.Stderr = os.Stderr
+	stdin, err := b.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := b.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	b.stdin = stdin
+	if err := b.cmd.Start(); err != nil {
+		return nil, err
+	}
+	go b.read(stdout)
+
+	if err := b.send(HostControl{Type: TypeHello, Protocol: ProtocolVersion}); err != nil {
+		b.Close()
+		return nil, err
+	}
+	hello, err := b.receive(func(msg BotMessage) bool { return msg.Type == TypeHello })
+	if err != nil {
+		b.Close()
+		return nil, err
+	}
+	if hello.Protocol != ProtocolVersion {
+		b.Close()
+		return nil, fmt.Errorf("%w: speaks protocol %d, not %d", ErrBotProtocol, hello.Protocol, ProtocolVersion)
+	}
+	if hello.Name != "" {
+		b.name = hello.Name
+	}
+	return b, nil
+}
+
+// read passes the bots lines on until it closes stdout
+func (b *External) read(stdout io.Reader) {
+	defer close(b.messages)
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 4096), maxLine)
+	for scanner.Scan() {
+		var msg BotMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			log.Printf("%v: ignoring %q: %v", b.name, scanner.Text(), err)
+			continue
+		}
+		if msg.Type == TypeLog {
+			log.Printf("%v: %v", b.name, msg.Message)
+			continue
+		}
+		b.messages <- msg
+	}
+}
+
+// send writes a HostMessage or HostControl as a line
+func (b *External) send(msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = b.stdin.Write(append(data, '\n'))
+	return err
+}
+
+// receive waits for a message wanted is true for, dropping the others
+func (b *External) receive(wanted func(BotMessage) bool) (BotMessage, error) {
+	timeout := time.NewTimer(b.TimeLimit)
+	defer timeout.Stop()
+	for {
+		select {
+		case msg, ok := <-b.messages:
+			if !ok {
+				return BotMessage{}, ErrBotExited
+			}
+			if wanted(msg) {
+				return msg, nil
+			}
+		case <-timeout.C:
+			return BotMessage{}, ErrBotTimeout
+		}
+	}
+}
+
+// Name is the kind of bot, as passed to New
+func (b *External) Name() string {
+	return ExternalPrefix + b.command
+}
+
+// BotName is the name the bot gave in its hello
+func (b *External) BotName() string {
+	return b.name
+}
+
+// Ask sends the position to the bot and returns its move, or why it gave none
+func (b *External) Ask(s rules.State) (rules.Move, error) {
+	b.id++
+	position, err := NewPosition(b.id, s, int(b.TimeLimit/time.Millisecond))
+	if err != nil {
+		return rules.Move{}, err
+	}
+	if err := b.send(position); err != nil {
+		return rules.Move{}, err
+	}
+
+	// an answer to an earlier position that came too late is dropped here
+	id := b.id
+	msg, err := b.receive(func(msg BotMessage) bool { return msg.Type == TypeMove && msg.ID == id })
+	if err != nil {
+		return rules.Move{}, err
+	}
+	m, err := notation.ParseMove(msg.Move)
+	if err != nil {
+		return rules.Move{}, fmt.Errorf("%w: %v", ErrBotProtocol, err)
+	}
+	if _, _, err := rules.Apply(s, m); err != nil {
+		return rules.Move{}, fmt.Errorf("%w: %v is not legal: %v", ErrBotProtocol, msg.Move, err)
+	}
+	return m, nil
+}
+
+// NextMove asks the bot, and ends the turn if it has no good answer
+func (b *External) NextMove(s rules.State) rules.Move {
+	m, err := b.Ask(s)
+	if err != nil {
+		log.Printf("%v: %v, ending the turn", b.name, err)
+		return rules.EndTurnMove
+	}
+	return m
+}
+
+// Close asks the bot to quit, and kills it if it does not
+func (b *External) Close() error {
+	var err error
+	b.closeOnce.Do(func() {
+		b.send(HostControl{Type: TypeQuit})
+		b.stdin.Close()
+
+		// Wait closes stdout, so it has to wait until read is done with it. read
+		// closes messages when it is, and is kept from blocking on it till then.
+		done := make(chan struct{})
+		go func() {
+			for range b.messages {
+			}
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(quitWait):
+			b.cmd.Process.Kill()
+			<-done
+		}
+		err = b.cmd.Wait()
+	})
+	return err
+}
+
+// Close stops b if it runs outside of this program, other bots need nothing
+func Close(b Bot) error {
+	if closer, ok := b.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package bot
+
+import (
+	"math/rand"
+
+	"sixDivides/rules"
+)
+
+// Greedy plays the move that looks best straight away. It goes for captures
+// and damage to other players first, then for pieces that generate more
+// actions, and never looks further ahead than the one move.
+type Greedy struct {
+	rng *rand.Rand
+}
+
+func NewGreedy(seed int64) *Greedy {
+	return &Greedy{rng: rand.New(rand.NewSource(seed))}
+}
+
+func (b *Greedy) Name() string {
+	return "greedy"
+}
+
+func (b *Greedy) NextMove(s rules.State) rules.Move {
+	moves := rules.LegalMoves(s, s.Turn)
+	if len(moves) == 0 {
+		return rules.EndTurnMove
+	}
+
+	// pick randomly between the moves with the best score, so it does not play the same game every time
+	var best []rules.Move
+	bestScore := 0
+	for _, m := range moves {
+		after, _, err := rules.Apply(s, m)
+		if err != nil {
+			continue
+		}
+		score := greedyScore(s, after, s.Turn)
+		if len(best) == 0 || score > bestScore {
+			best = []rules.Move{m}
+			bestScore = score
+		} else if score == bestScore {
+			best = append(best, m)
+		}
+	}
+	if len(best) == 0 {
+		return rules.EndTurnMove
+	}
+	return best[b.rng.Intn(len(best))]
+}
+
+// greedyScore is how much better off the player is after the move
+func greedyScore(before, after rules.State, player int) int {
+	if after.GameOver && after.Winner == player {
+		return 1_000_000
+	}
+	if after.Players[player].Eliminated {
+		return -1_000_000
+	}
+
+	score := 0
+	// removing pieces is worth the most, then taking value off them
+	score += 100 * (after.Players[player].Captured - before.Players[player].Captured)
+	score += 20 * (enemyValue(before, player) - enemyValue(after, player))
+	// growing, and pieces that make more actions next turn
+	score += 12 * (ownValue(after, player) - ownValue(before, player))
+	score += 6 * (rules.ActionsFor(after.Players[player]) - rules.ActionsFor(before.Players[player]))
+	// gatherers can not take pieces, so keep some soldiers and bring them towards the other players
+	score += 8 * (min(soldiers(after, player), 4) - min(soldiers(before, player), 4))
+	score += 2 * (soldierDistance(before, player) - soldierDistance(after, player))
+	return score
+}
+
+// soldiers is the number of pieces the player can attack with
+func soldiers(s rules.State, player int) int {
+	count := 0
+	for _, piece := range s.Players[player].Pieces {
+		if piece.Value == 2 || piece.Value == 4 {
+			count++
+		}
+	}
+	return count
+}
+
+// soldierDistance adds up how many steps each soldier is from its nearest enemy piece
+func soldierDistance(s rules.State, player int) int {
+	total := 0
+	for _, piece := range s.Players[player].Pieces {
+		if piece.Value != 2 && piece.Value != 4 {
+			continue
+		}
+		nearest := -1
+		for i, other := range s.Players {
+			if i == player {
+				continue
+			}
+			for _, enemy := range other.Pieces {
+				d := abs(piece.Position.X-enemy.Position.X) + abs(piece.Position.Y-enemy.Position.Y)
+				if nearest == -1 || d < nearest {
+					nearest = d
+				}
+			}
+		}
+		total += max(nearest, 0)
+	}
+	return total
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// ownValue is the total value of the players pieces
+func ownValue(s rules.State, player int) int {
+	total := 0
+	for _, piece := range s.Players[player].Pieces {
+		total += piece.Value
+	}
+	return total
+}
+
+// enemyValue is the total value of every other players pieces
+func enemyValue(s rules.State, player int) int {
+	total := 0
+	for i := range s.Players {
+		if i != player {
+			total += ownValue(s, i)
+		}
+	}
+	return total
+}
@@ -0,0 +1,201 @@
+package bot
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"time"
+
+	"sixDivides/rules"
+)
+
+// MCTSDifficulties are the mcts levels offered to players, easiest first. They
+// are limited by iterations so a game can be repeated from its seed, the time
+// limit is only there to keep slow machines responsive.
+var MCTSDifficulties = []Difficulty{
+	{Name: "easy", Iterations: 200, TimeLimit: 500 * time.Millisecond},
+	{Name: "normal", Iterations: 1000, TimeLimit: 2 * time.Second},
+	{Name: "hard", Iterations: 4000, TimeLimit: 5 * time.Second},
+}
+
+const (
+	// playoutActions is how many random actions a playout runs before the position is scored
+	playoutActions = 8
+	// exploration is the UCT constant, higher tries more of the less visited moves
+	exploration = 0.7
+	// shareScale is how far ahead of the others, in Evaluate points, counts as a good position
+	shareScale = 100.0
+)
+
+// MCTS is a Monte Carlo Tree Search bot. It plays out random games from the
+// moves that have done well so far, and picks the move it explored the most.
+// Each player is assumed to play for their own share of the result (max-n),
+// which suits games of three and four players better than a single opponent.
+//
+// With a seed and an iteration limit it always picks the same moves for the
+// same game, unless the time limit runs out first.
+type MCTS struct {
+	difficulty Difficulty
+	rng        *rand.Rand
+}
+
+func NewMCTS(difficulty Difficulty, seed int64) *MCTS {
+	return &MCTS{difficulty: difficulty, rng: rand.New(rand.NewSource(seed))}
+}
+
+func (b *MCTS) Name() string {
+	return "mcts-" + b.difficulty.Name
+}
+
+// mctsNode is a position in the search tree, reached by playing move from its parent
+type mctsNode struct {
+	state    rules.State
+	move     rules.Move
+	parent   *mctsNode
+	children []*mctsNode
+	untried  []rules.Move
+	visits   int
+	// rewards is the total result of each player over the playouts through this node
+	rewards []float64
+}
+
+func newNode(s rules.State, m rules.Move, parent *mctsNode) *mctsNode {
+	node := &mctsNode{state: s, move: m, parent: parent, rewards: make([]float64, len(s.Players))}
+	if !s.GameOver {
+		node.untried = searchMoves(s)
+	}
+	return node
+}
+
+func (b *MCTS) NextMove(s rules.State) rules.Move {
+	moves := searchMoves(s)
+	if len(moves) == 1 {
+		return moves[0]
+	}
+
+	root := newNode(s, rules.EndTurnMove, nil)
+	deadline := time.Now().Add(b.difficulty.TimeLimit)
+	for i := 0; b.difficulty.Iterations == 0 || i < b.difficulty.Iterations; i++ {
+		if i > 0 && time.Now().After(deadline) {
+			break
+		}
+		// the browser build runs every goroutine on one thread, so give the game a chance to draw
+		runtime.Gosched()
+
+		// follow the most promising moves down to a position that has moves not yet tried
+		node := root
+		for len(node.untried) == 0 && len(node.children) > 0 {
+			node = node.selectChild()
+		}
+
+		// try one of them
+		if len(node.untried) > 0 {
+			pick := b.rng.Intn(len(node.untried))
+			m := node.untried[pick]
+			node.untried = append(node.untried[:pick], node.untried[pick+1:]...)
+			n, _, err := rules.Apply(node.state, m)
+			if err != nil {
+				continue
+			}
+			child := newNode(n, m, node)
+			node.children = append(node.children, child)
+			node = child
+		}
+
+		rewards := b.playout(node.state)
+		for ; node != nil; node = node.parent {
+			node.visits++
+			for p, reward := range rewards {
+				node.rewards[p] += reward
+			}
+		}
+	}
+
+	if len(root.children) == 0 {
+		return moves[0]
+	}
+	best := root.children[0]
+	for _, child := range root.children {
+		if child.visits > best.visits {
+			best = child
+		}
+	}
+	return best.move
+}
+
+// selectChild picks the child with the best upper confidence bound for the player choosing the move
+func (node *mctsNode) selectChild() *mctsNode {
+	player := node.state.Turn
+	var best *mctsNode
+	bestScore := math.Inf(-1)
+	for _, child := range node.children {
+		score := child.rewards[player]/float64(child.visits) +
+			exploration*math.Sqrt(math.Log(float64(node.visits))/float64(child.visits))
+		if score > bestScore {
+			best = child
+			bestScore = score
+		}
+	}
+	return best
+}
+
+// playout plays random actions from the position, and returns each players share of the result
+func (b *MCTS) playout(s rules.State) []float64 {
+	for i := 0; i < playoutActions && !s.GameOver; i++ {
+		m := b.playoutMove(s)
+		n, _, err := rules.Apply(s, m)
+		if err != nil {
+			break
+		}
+		s = n
+	}
+	return shares(s)
+}
+
+// playoutMove picks a random action, taking a capture when there is one as
+// purely random games rarely look like real ones
+func (b *MCTS) playoutMove(s rules.State) rules.Move {
+	moves := rules.LegalMoves(s, s.Turn)
+	if len(moves) == 0 {
+		return rules.EndTurnMove
+	}
+	var captures []rules.Move
+	for _, m := range moves {
+		if m.Kind == rules.KindAttack || m.Kind == rules.KindOutpostStrike {
+			captures = append(captures, m)
+		}
+	}
+	if len(captures) > 0 && b.rng.Intn(2) == 0 {
+		return captures[b.rng.Intn(len(captures))]
+	}
+	return moves[b.rng.Intn(len(moves))]
+}
+
+// shares scores the result for each player between 0 and 1. A finished game
+// is 1 for the winner, or split evenly on a draw. Otherwise each player still
+// in the game scores by how far ahead of the average player they are placed.
+func shares(s rules.State) []float64 {
+	result := make([]float64, len(s.Players))
+	if s.GameOver {
+		if s.Winner != rules.NoWinner {
+			result[s.Winner] = 1
+			return result
+		}
+		for p := range result {
+			result[p] = 1 / float64(len(result))
+		}
+		return result
+	}
+
+	remaining := s.Remaining()
+	standings := make([]float64, len(s.Players))
+	average := 0.0
+	for _, p := range remaining {
+		standings[p] = float64(standing(s, p))
+		average += standings[p] / float64(len(remaining))
+	}
+	for _, p := range remaining {
+		result[p] = 1 / (1 + math.Exp(-(standings[p]-average)/shareScale))
+	}
+	return result
+}
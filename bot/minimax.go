@@ -0,0 +1,156 @@
+package bot
+
+import (
+	"runtime"
+	"time"
+
+	"sixDivides/rules"
+)
+
+// Difficulty is how hard a search bot thinks, and how long it may take over each action
+type Difficulty struct {
+	Name string
+	// Depth is the number of actions the minimax bot searches ahead, across turns
+	Depth int
+	// Iterations is the number of playouts the mcts bot runs, 0 runs as many as fit in the time limit
+	Iterations int
+	// TimeLimit stops the search early, keeping the best move found so far
+	TimeLimit time.Duration
+}
+
+// Difficulties are the minimax levels offered to players, easiest first
+var Difficulties = []Difficulty{
+	{Name: "easy", Depth: 1, TimeLimit: 100 * time.Millisecond},
+	{Name: "normal", Depth: 3, TimeLimit: 500 * time.Millisecond},
+	{Name: "hard", Depth: 5, TimeLimit: 1500 * time.Millisecond},
+}
+
+// checkEvery is how many positions are searched between checks of the time limit
+const checkEvery = 256
+
+// Minimax searches the moves ahead with alpha-beta pruning. Every action is a
+// ply, so it plans across all of the actions in a turn and into the next
+// players turn. With more than two players it is paranoid, and assumes all of
+// the others play against it.
+type Minimax struct {
+	difficulty Difficulty
+	player     int
+	deadline   time.Time
+	nodes      int
+	timedOut   bool
+}
+
+func NewMinimax(difficulty Difficulty) *Minimax {
+	return &Minimax{difficulty: difficulty}
+}
+
+func (b *Minimax) Name() string {
+	return "minimax-" + b.difficulty.Name
+}
+
+func (b *Minimax) NextMove(s rules.State) rules.Move {
+	moves := searchMoves(s)
+	if len(moves) == 1 {
+		return moves[0]
+	}
+
+	b.player = s.Turn
+	b.deadline = time.Now().Add(b.difficulty.TimeLimit)
+	b.timedOut = false
+
+	// deepen one action at a time, so there is always a finished search to fall back on
+	best := moves[0]
+	for depth := 1; depth <= b.difficulty.Depth; depth++ {
+		move, ok := b.searchRoot(s, moves, depth)
+		if !ok {
+			break
+		}
+		best = move
+		// look at the best move first next time round, it prunes the most
+		moves = moveToFront(moves, best)
+	}
+	return best
+}
+
+// searchRoot finds the best move at the depth, and reports false if it ran out of time
+func (b *Minimax) searchRoot(s rules.State, moves []rules.Move, depth int) (rules.Move, bool) {
+	alpha, beta := -scoreWin-1, scoreWin+1
+	best := moves[0]
+	for _, m := range moves {
+		n, _, err := rules.Apply(s, m)
+		if err != nil {
+			continue
+		}
+		score := b.search(n, depth-1, alpha, beta)
+		if b.timedOut && depth > 1 {
+			return best, false
+		}
+		if score > alpha {
+			alpha = score
+			best = m
+		}
+	}
+	return best, true
+}
+
+// search is the score of the position for the bots player, looking depth actions ahead
+func (b *Minimax) search(s rules.State, depth int, alpha int, beta int) int {
+	b.nodes++
+	if b.nodes%checkEvery == 0 {
+		b.timedOut = time.Now().After(b.deadline)
+		// the browser build runs every goroutine on one thread, so give the game a chance to draw
+		runtime.Gosched()
+	}
+	if depth == 0 || s.GameOver || b.timedOut {
+		return Evaluate(s, b.player)
+	}
+
+	maximising := s.Turn == b.player
+	for _, m := range searchMoves(s) {
+		n, _, err := rules.Apply(s, m)
+		if err != nil {
+			continue
+		}
+		score := b.search(n, depth-1, alpha, beta)
+		if maximising && score > alpha {
+			alpha = score
+		} else if !maximising && score < beta {
+			beta = score
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	if maximising {
+		return alpha
+	}
+	return beta
+}
+
+// searchMoves are the legal moves with captures first, as they are the most
+// likely to be best and so prune the most, and ending the turn last
+func searchMoves(s rules.State) []rules.Move {
+	legal := rules.LegalMoves(s, s.Turn)
+	moves := make([]rules.Move, 0, len(legal)+1)
+	for _, m := range legal {
+		if m.Kind == rules.KindAttack || m.Kind == rules.KindOutpostStrike {
+			moves = append(moves, m)
+		}
+	}
+	for _, m := range legal {
+		if m.Kind != rules.KindAttack && m.Kind != rules.KindOutpostStrike {
+			moves = append(moves, m)
+		}
+	}
+	return append(moves, rules.EndTurnMove)
+}
+
+func moveToFront(moves []rules.Move, m rules.Move) []rules.Move {
+	ordered := []rules.Move{m}
+	for _, other := range moves {
+		if other != m {
+			ordered = append(ordered, other)
+		}
+	}
+	return ordered
+}
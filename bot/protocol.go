@@ -0,0 +1,185 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+
+	"sixDivides/notation"
+	"sixDivides/rules"
+)
+
+// The external bot protocol lets a bot run as its own program, written in any
+// language, in the spirit of UCI for chess. The host (the client, the server,
+// sixsim or the sixbotcheck harness) starts the program and talks to it over
+// stdin and stdout, one json object per line. Anything the bot writes to
+// stderr is passed through to the hosts log.
+//
+// The host starts with a hello, and the bot answers with its own hello:
+//
+//	host: {"type":"hello","protocol":1}
+//	bot:  {"type":"hello","protocol":1,"name":"my bot"}
+//
+// Then for each action the host sends the position, and the bot answers with
+// the move it wants to play, in notation, and the id of the position. The
+// move has to be one of the legal moves, "pass" ends the turn. The position
+// has the whole state, so a bot can run its own rules on it, or just pick
+// from legal and look at the pieces.
+//
+//	host: {"type":"position","id":7,"turn":0,"legal":["b2*b3","pass"],"players":[...],...}
+//	bot:  {"type":"move","id":7,"move":"b2*b3"}
+//
+// A bot may send {"type":"log","message":"..."} lines at any time, which the
+// host logs. When it is finished with the bot the host sends
+// {"type":"quit"} and closes stdin, and the bot should exit.
+//
+// A bot that answers too late, with a move that is not legal, or not at all,
+// has its turn ended for it.
+
+// ProtocolVersion is the version of the external bot protocol described above
+const ProtocolVersion = 1
+
+// message types of the external bot protocol
+const (
+	TypeHello    = "hello"
+	TypePosition = "position"
+	TypeMove     = "move"
+	TypeLog      = "log"
+	TypeQuit     = "quit"
+)
+
+// HostControl is the hello and the quit the host sends, which carry no position
+type HostControl struct {
+	Type     string `json:"type"`
+	Protocol int    `json:"protocol,omitempty"`
+}
+
+// HostMessage is sent by the host to an external bot, it has the fields of
+// every message so a bot can read them all into one
+type HostMessage struct {
+	Type     string `json:"type"`
+	Protocol int    `json:"protocol,omitempty"`
+	// ID is echoed back in the move, so a late answer is not taken for the next position
+	ID int `json:"id,omitempty"`
+	// Turn is the index of the player to move for
+	Turn        int `json:"turn"`
+	TurnsPlayed int `json:"turnsPlayed"`
+	// Legal is every move the player can make, in notation
+	Legal []string `json:"legal,omitempty"`
+	// Columns and Rows are the number of tiles across and down the board
+	Columns int              `json:"columns,omitempty"`
+	Rows    int              `json:"rows,omitempty"`
+	Players []ProtocolPlayer `json:"players,omitempty"`
+	Pieces  []ProtocolPiece  `json:"pieces,omitempty"`
+	// TimeLimit is how many milliseconds the bot has to answer
+	TimeLimit int `json:"timeLimitMs,omitempty"`
+}
+
+// ProtocolPlayer is a player and their counts
+type ProtocolPlayer struct {
+	Name       string `json:"name"`
+	Actions    int    `json:"actions"`
+	Eliminated bool   `json:"eliminated"`
+	Captured   int    `json:"captured"`
+	// StartX and StartY are the players starting corner
+	StartX int `json:"startX"`
+	StartY int `json:"startY"`
+}
+
+// ProtocolPiece is a piece on the board, X and Y count from 0 at the top left
+type ProtocolPiece struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Value  int `json:"value"`
+	Player int `json:"player"`
+}
+
+// BotMessage is sent by an external bot to the host
+type BotMessage struct {
+	Type     string `json:"type"`
+	Protocol int    `json:"protocol,omitempty"`
+	Name     string `json:"name,omitempty"`
+	ID       int    `json:"id,omitempty"`
+	Move     string `json:"move,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// NewPosition describes the state for an external bot
+func NewPosition(id int, s rules.State, timeLimitMs int) (HostMessage, error) {
+	msg := HostMessage{
+		Type:        TypePosition,
+		ID:          id,
+		Turn:        s.Turn,
+		TurnsPlayed: s.TurnsPlayed,
+		Legal:       []string{},
+		Columns:     s.Board.Width + 1,
+		Rows:        s.Board.Height + 1,
+		Pieces:      []ProtocolPiece{},
+		TimeLimit:   timeLimitMs,
+	}
+	for _, m := range LegalActions(s) {
+		move, err := notation.FormatMove(m)
+		if err != nil {
+			return HostMessage{}, err
+		}
+		msg.Legal = append(msg.Legal, move)
+	}
+	for _, player := range s.Players {
+		msg.Players = append(msg.Players, ProtocolPlayer{
+			Name:       player.Name,
+			Actions:    player.Actions,
+			Eliminated: player.Eliminated,
+			Captured:   player.Captured,
+			StartX:     player.StartingPosition.X,
+			StartY:     player.StartingPosition.Y,
+		})
+		for _, piece := range player.Pieces {
+			msg.Pieces = append(msg.Pieces, ProtocolPiece{X: piece.Position.X, Y: piece.Position.Y, Value: piece.Value, Player: piece.PlayerIndex})
+		}
+	}
+	return msg, nil
+}
+
+// State rebuilds the state of a position, for bots written in go
+func (msg HostMessage) State() (rules.State, error) {
+	if err := rules.CheckBoardSize(msg.Columns-1, msg.Rows-1); err != nil {
+		return rules.State{}, err
+	}
+	if msg.Turn < 0 || msg.Turn >= len(msg.Players) {
+		return rules.State{}, errors.New("position has no player for the current turn")
+	}
+
+	s := rules.State{
+		Board:       rules.CreateBoard(msg.Columns-1, msg.Rows-1),
+		Players:     make([]rules.Player, len(msg.Players)),
+		Turn:        msg.Turn,
+		Winner:      rules.NoWinner,
+		TurnsPlayed: msg.TurnsPlayed,
+	}
+	for i, player := range msg.Players {
+		s.Players[i] = rules.Player{
+			Name:             player.Name,
+			Actions:          player.Actions,
+			PlayerIndex:      i,
+			StartingPosition: rules.Position{X: player.StartX, Y: player.StartY},
+			Eliminated:       player.Eliminated,
+			Captured:         player.Captured,
+		}
+	}
+	for _, piece := range msg.Pieces {
+		position := rules.Position{X: piece.X, Y: piece.Y}
+		if piece.Player < 0 || piece.Player >= len(s.Players) || !s.Board.InBounds(position) {
+			return rules.State{}, fmt.Errorf("invalid piece %+v", piece)
+		}
+		s.Players[piece.Player].Pieces = append(s.Players[piece.Player].Pieces, rules.Piece{Value: piece.Value, PlayerIndex: piece.Player, Position: position})
+	}
+	s.SyncBoard()
+	return s, nil
+}
+
+// LegalActions is every move the current player can make, including ending their turn
+func LegalActions(s rules.State) []rules.Move {
+	if s.GameOver {
+		return nil
+	}
+	return append(rules.LegalMoves(s, s.Turn), rules.EndTurnMove)
+}
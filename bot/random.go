@@ -0,0 +1,28 @@
+package bot
+
+import (
+	"math/rand"
+
+	"sixDivides/rules"
+)
+
+// Random plays any legal move, it is the easiest opponent and a baseline for the others
+type Random struct {
+	rng *rand.Rand
+}
+
+func NewRandom(seed int64) *Random {
+	return &Random{rng: rand.New(rand.NewSource(seed))}
+}
+
+func (b *Random) Name() string {
+	return "random"
+}
+
+func (b *Random) NextMove(s rules.State) rules.Move {
+	moves := rules.LegalMoves(s, s.Turn)
+	if len(moves) == 0 {
+		return rules.EndTurnMove
+	}
+	return moves[b.rng.Intn(len(moves))]
+}
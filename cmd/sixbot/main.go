@@ -0,0 +1,87 @@
+// Command sixbot is the reference external bot. It speaks the external bot
+// protocol described in the bot package over stdin and stdout, and plays with
+// one of the built in bots, so it doubles as an example for bots written in
+// other languages.
+//
+//	go build -o sixbot ./cmd/sixbot
+//	sixbot -kind minimax-normal
+//
+// Pick it in the client by adding "./sixbot -kind greedy" to externalBots in
+// settings.json, or run it anywhere a bot kind is taken as exec:./sixbot.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"sixDivides/bot"
+	"sixDivides/notation"
+)
+
+func main() {
+	kind := flag.String("kind", "greedy", "built in bot to play with, any of "+strings.Join(bot.Kinds, ", "))
+	flag.Parse()
+	// stdout is for the protocol, everything else goes to stderr which the host shows in its log
+	log.SetOutput(os.Stderr)
+	log.SetPrefix("sixbot: ")
+
+	// checked before bot.New, which would start the program
+	if strings.HasPrefix(*kind, bot.ExternalPrefix) {
+		log.Fatalf("can not play as %q, sixbot only plays the built in bots", *kind)
+	}
+	b, err := bot.New(*kind, time.Now().UnixNano())
+	if err != nil {
+		log.Fatalf("can not play as %q: %v", *kind, err)
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	in := bufio.NewScanner(os.Stdin)
+	in.Buffer(make([]byte, 0, 4096), 1<<20)
+	for in.Scan() {
+		var msg bot.HostMessage
+		if err := json.Unmarshal(in.Bytes(), &msg); err != nil {
+			log.Printf("ignoring %q: %v", in.Text(), err)
+			continue
+		}
+
+		switch msg.Type {
+		case bot.TypeHello:
+			if msg.Protocol != bot.ProtocolVersion {
+				log.Printf("host speaks protocol %d, answering with %d anyway", msg.Protocol, bot.ProtocolVersion)
+			}
+			send(out, bot.BotMessage{Type: bot.TypeHello, Protocol: bot.ProtocolVersion, Name: "sixbot " + b.Name()})
+		case bot.TypePosition:
+			send(out, bot.BotMessage{Type: bot.TypeMove, ID: msg.ID, Move: play(b, msg)})
+		case bot.TypeQuit:
+			return
+		default:
+			log.Printf("ignoring unknown message %q", msg.Type)
+		}
+	}
+}
+
+// play picks the move for the position, ending the turn if the position is broken
+func play(b bot.Bot, msg bot.HostMessage) string {
+	s, err := msg.State()
+	if err != nil {
+		log.Printf("bad position %v: %v", msg.ID, err)
+		return notation.PassToken
+	}
+	move, err := notation.FormatMove(b.NextMove(s))
+	if err != nil {
+		log.Printf("could not write the move for position %v: %v", msg.ID, err)
+		move = notation.PassToken
+	}
+	return move
+}
+
+func send(out *json.Encoder, msg bot.BotMessage) {
+	if err := out.Encode(msg); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,184 @@
+// Command sixbotcheck runs an external bot through the checks every bot
+// should pass before it is used in games: the hello, legal answers in time
+// for starting and mid game positions with 2, 3 and 4 players, a whole game
+// against the random bot, and exiting when told to quit. It prints PASS or
+// FAIL for each check and exits with 1 if any failed.
+//
+//	go build -o sixbot ./cmd/sixbot
+//	go run ./cmd/sixbotcheck ./sixbot -kind greedy
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"sixDivides/bot"
+	"sixDivides/rules"
+)
+
+// checkSeats are the seatings positions are checked with, as for rules.NewGame
+var checkSeats = [][]int{{-1, 2, 1, -1}, {1, 2, 3, -1}, {1, 2, 3, 4}}
+
+// checkBoards are the highest tile indexes of the boards positions are checked on
+var checkBoards = []rules.Position{{X: 7, Y: 7}, {X: 5, Y: 5}, {X: 11, Y: 7}}
+
+func main() {
+	timeLimit := flag.Duration("time", bot.DefaultTimeLimit, "how long the bot has to answer each position")
+	positions := flag.Int("positions", 20, "mid game positions to check for each board and seating")
+	maxActions := flag.Int("max-actions", 2000, "actions after which the whole game check gives up")
+	seed := flag.Int64("seed", 1, "seed for the random positions and the random opponent")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: sixbotcheck [flags] command [args]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command := strings.Join(flag.Args(), " ")
+
+	c := checker{timeLimit: *timeLimit}
+	b, ok := c.handshake(command)
+	if !ok {
+		os.Exit(1)
+	}
+	rng := rand.New(rand.NewSource(*seed))
+	c.startPositions(b)
+	c.midGamePositions(b, rng, *positions)
+	c.wholeGame(b, rng.Int63(), *maxActions)
+	c.quit(b)
+
+	if c.failed > 0 {
+		fmt.Printf("%v of %v checks failed\n", c.failed, c.checks)
+		os.Exit(1)
+	}
+	fmt.Printf("all %v checks passed\n", c.checks)
+}
+
+// checker counts and prints the result of each check
+type checker struct {
+	timeLimit time.Duration
+	checks    int
+	failed    int
+}
+
+func (c *checker) report(name string, err error) bool {
+	c.checks++
+	if err != nil {
+		c.failed++
+		fmt.Printf("FAIL %v: %v\n", name, err)
+		return false
+	}
+	fmt.Printf("PASS %v\n", name)
+	return true
+}
+
+func (c *checker) handshake(command string) (*bot.External, bool) {
+	start := time.Now()
+	b, err := bot.NewExternal(command)
+	if err != nil {
+		return nil, c.report("hello", err)
+	}
+	b.TimeLimit = c.timeLimit
+	return b, c.report(fmt.Sprintf("hello from %q in %v", b.BotName(), time.Since(start).Round(time.Millisecond)), nil)
+}
+
+// ask times the bots answer to the position, Ask makes sure it is legal and in time
+func (c *checker) ask(b *bot.External, s rules.State) (rules.Move, time.Duration, error) {
+	start := time.Now()
+	m, err := b.Ask(s)
+	return m, time.Since(start), err
+}
+
+func (c *checker) startPositions(b *bot.External) {
+	for _, board := range checkBoards {
+		for _, seats := range checkSeats {
+			s := rules.NewGame(board.X, board.Y, seats)
+			_, took, err := c.ask(b, s)
+			c.report(fmt.Sprintf("start of %v on %vx%v in %v", players(s), board.X+1, board.Y+1, took.Round(time.Millisecond)), err)
+		}
+	}
+}
+
+// midGamePositions plays random moves into each game, and checks the bot on where they end up
+func (c *checker) midGamePositions(b *bot.External, rng *rand.Rand, count int) {
+	random := bot.NewRandom(rng.Int63())
+	for _, board := range checkBoards {
+		for _, seats := range checkSeats {
+			var err error
+			var slowest time.Duration
+			for i := 0; i < count && err == nil; i++ {
+				s := randomPosition(board, seats, random, rng.Intn(80))
+				var took time.Duration
+				_, took, err = c.ask(b, s)
+				slowest = max(slowest, took)
+			}
+			c.report(fmt.Sprintf("%v mid game positions of %v on %vx%v, slowest %v", count, players(rules.NewGame(board.X, board.Y, seats)), board.X+1, board.Y+1, slowest.Round(time.Millisecond)), err)
+		}
+	}
+}
+
+// randomPosition is a game after the random bot has played up to actions for everyone
+func randomPosition(board rules.Position, seats []int, random bot.Bot, actions int) rules.State {
+	s := rules.NewGame(board.X, board.Y, seats)
+	for i := 0; i < actions; i++ {
+		n, _, err := rules.Apply(s, random.NextMove(s))
+		if err != nil || n.GameOver {
+			break
+		}
+		s = n
+	}
+	return s
+}
+
+// wholeGame has the bot play a 2 player game against the random bot
+func (c *checker) wholeGame(b *bot.External, seed int64, maxActions int) {
+	random := bot.NewRandom(seed)
+	s := rules.NewGame(7, 7, checkSeats[0])
+	// the external bot is the first player
+	var err error
+	actions := 0
+	for !s.GameOver && actions < maxActions && err == nil {
+		var m rules.Move
+		if s.Turn == 0 {
+			m, _, err = c.ask(b, s)
+		} else {
+			m = random.NextMove(s)
+		}
+		if err == nil {
+			s, _, err = rules.Apply(s, m)
+		}
+		actions++
+	}
+
+	name := fmt.Sprintf("whole game against random, %v turns", s.TurnsPlayed)
+	switch {
+	case err != nil:
+	case !s.GameOver:
+		name += ", unfinished"
+	case s.Winner == 0:
+		name += ", won"
+	default:
+		name += ", lost"
+	}
+	c.report(name, err)
+}
+
+func (c *checker) quit(b *bot.External) {
+	start := time.Now()
+	err := b.Close()
+	took := time.Since(start)
+	if err != nil {
+		err = fmt.Errorf("did not exit cleanly after %v: %w", took.Round(time.Millisecond), err)
+	}
+	c.report("quit", err)
+}
+
+func players(s rules.State) string {
+	return fmt.Sprintf("%v players", len(s.Players))
+}
@@ -0,0 +1,103 @@
+// Command sixsim plays many games between bots without a window, and reports
+// how balanced the rules are: how often each seat and starting corner wins,
+// how long games last, how much going first is worth and how often games end
+// without a winner.
+//
+//	go run ./cmd/sixsim -games 2000 -policies random,greedy -boards 8x8,10x10
+//	go run ./cmd/sixsim -format csv -out balance.csv
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"sixDivides/bot"
+	"sixDivides/rules"
+)
+
+func main() {
+	games := flag.Int("games", 1000, "games to play for each policy, board and seats")
+	policies := flag.String("policies", "random,greedy", "comma separated bots to play every seat, any of "+strings.Join(bot.Kinds, ", ")+", or "+bot.ExternalPrefix+"command for an external bot")
+	boards := flag.String("boards", "8x8", "comma separated board sizes, as columns x rows")
+	seats := flag.String("seats", "1 -1 -1 2;1 2 3 -1;1 2 3 4", "semicolon separated seats, the player number in each corner section or -1 for empty")
+	maxActions := flag.Int("max-actions", 5000, "actions after which a game is given up as unfinished")
+	workers := flag.Int("workers", runtime.NumCPU(), "games played at the same time")
+	seed := flag.Int64("seed", 1, "seed for the first game, each game after uses the next one")
+	format := flag.String("format", "text", "report format, text, csv or json")
+	out := flag.String("out", "", "file to write the report to, instead of stdout")
+	flag.Parse()
+
+	configs, err := parseConfigs(*policies, *boards, *seats)
+	if err != nil {
+		log.Fatal(err)
+	}
+	write, ok := writers[*format]
+	if !ok {
+		log.Fatalf("unknown format %q, use text, csv or json", *format)
+	}
+
+	results := simulate(configs, *games, *maxActions, *workers, *seed)
+	reports := make([]configReport, len(configs))
+	for i, c := range configs {
+		reports[i] = summarise(c, results[i])
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := write(w, reports); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseConfigs makes a config for every combination of policy, board and seats
+func parseConfigs(policies string, boards string, seats string) ([]config, error) {
+	var configs []config
+	for _, policy := range strings.Split(policies, ",") {
+		policy = strings.TrimSpace(policy)
+		b, err := bot.New(policy, 0)
+		if err != nil {
+			return nil, err
+		}
+		bot.Close(b)
+		for _, board := range strings.Split(boards, ",") {
+			width, height, err := rules.ParseBoardSize(strings.TrimSpace(board))
+			if err != nil {
+				return nil, err
+			}
+			for _, seating := range strings.Split(seats, ";") {
+				s, err := parseSeats(seating)
+				if err != nil {
+					return nil, err
+				}
+				configs = append(configs, config{policy: policy, width: width, height: height, seats: s})
+			}
+		}
+	}
+	return configs, nil
+}
+
+// parseSeats reads the player number in each of the four corner sections
+func parseSeats(seating string) ([]int, error) {
+	var seats []int
+	for _, field := range strings.Fields(seating) {
+		seat, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid seats %q: %v", seating, err)
+		}
+		seats = append(seats, seat)
+	}
+	return seats, rules.CheckSeats(seats)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// configReport is the summary of all the games of one config
+type configReport struct {
+	Policy     string `json:"policy"`
+	Board      string `json:"board"`
+	Seats      string `json:"seats"`
+	Games      int    `json:"games"`
+	Wins       int    `json:"wins"`
+	Draws      int    `json:"draws"`
+	Stalemates int    `json:"stalemates"`
+	Unfinished int    `json:"unfinished"`
+	// DrawRate counts both draws and stalemates, out of all the games
+	DrawRate       float64 `json:"drawRate"`
+	AverageTurns   float64 `json:"averageTurns"`
+	AverageActions float64 `json:"averageActions"`
+	// FirstPlayerAdvantage is how much more often player 1 wins than a fair share of the decided games
+	FirstPlayerAdvantage float64        `json:"firstPlayerAdvantage"`
+	Players              []playerReport `json:"players"`
+}
+
+// playerReport is how often one seat won
+type playerReport struct {
+	Player int    `json:"player"`
+	Corner string `json:"corner"`
+	Wins   int    `json:"wins"`
+	// WinRate is out of the games that had a winner
+	WinRate float64 `json:"winRate"`
+}
+
+func summarise(c config, results []gameResult) configReport {
+	seats := make([]string, len(c.seats))
+	for i, seat := range c.seats {
+		seats[i] = strconv.Itoa(seat)
+	}
+	report := configReport{
+		Policy: c.policy,
+		Board:  fmt.Sprintf("%vx%v", c.width+1, c.height+1),
+		Seats:  strings.Join(seats, " "),
+		Games:  len(results),
+	}
+	if len(results) == 0 {
+		return report
+	}
+
+	for i, corner := range results[0].corners {
+		report.Players = append(report.Players, playerReport{Player: i + 1, Corner: corner})
+	}
+	for _, result := range results {
+		switch {
+		case result.unfinished:
+			report.Unfinished++
+		case result.draw:
+			report.Draws++
+		case result.stalemate:
+			report.Stalemates++
+		default:
+			report.Wins++
+			report.Players[result.winner].Wins++
+		}
+		report.AverageTurns += float64(result.turns) / float64(len(results))
+		report.AverageActions += float64(result.actions) / float64(len(results))
+	}
+
+	report.DrawRate = float64(report.Draws+report.Stalemates) / float64(report.Games)
+	if report.Wins > 0 {
+		for i := range report.Players {
+			report.Players[i].WinRate = float64(report.Players[i].Wins) / float64(report.Wins)
+		}
+		report.FirstPlayerAdvantage = report.Players[0].WinRate - 1/float64(len(report.Players))
+	}
+	return report
+}
+
+// writers write the reports in each of the -format options
+var writers = map[string]func(io.Writer, []configReport) error{
+	"text": writeText,
+	"csv":  writeCSV,
+	"json": writeJSON,
+}
+
+func writeText(w io.Writer, reports []configReport) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, r := range reports {
+		fmt.Fprintf(tw, "%v on %v, seats %v, %v games\n", r.Policy, r.Board, r.Seats, r.Games)
+		fmt.Fprintf(tw, "  average length\t%.1f turns, %.1f actions\n", r.AverageTurns, r.AverageActions)
+		fmt.Fprintf(tw, "  draws and stalemates\t%v and %v, %.1f%%\n", r.Draws, r.Stalemates, r.DrawRate*100)
+		fmt.Fprintf(tw, "  unfinished\t%v\n", r.Unfinished)
+		fmt.Fprintf(tw, "  first player advantage\t%+.1f%%\n", r.FirstPlayerAdvantage*100)
+		for _, p := range r.Players {
+			fmt.Fprintf(tw, "  player %v, %v\t%v wins, %.1f%%\n", p.Player, p.Corner, p.Wins, p.WinRate*100)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+// writeCSV writes a row per player, with the summary of their config repeated on each row
+func writeCSV(w io.Writer, reports []configReport) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"policy", "board", "seats", "games", "wins", "draws", "stalemates", "unfinished", "drawRate",
+		"averageTurns", "averageActions", "firstPlayerAdvantage", "player", "corner", "playerWins", "playerWinRate"})
+	for _, r := range reports {
+		for _, p := range r.Players {
+			cw.Write([]string{r.Policy, r.Board, r.Seats, strconv.Itoa(r.Games), strconv.Itoa(r.Wins), strconv.Itoa(r.Draws),
+				strconv.Itoa(r.Stalemates), strconv.Itoa(r.Unfinished), formatFloat(r.DrawRate),
+				formatFloat(r.AverageTurns), formatFloat(r.AverageActions), formatFloat(r.FirstPlayerAdvantage),
+				strconv.Itoa(p.Player), p.Corner, strconv.Itoa(p.Wins), formatFloat(p.WinRate)})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeJSON(w io.Writer, reports []configReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(reports)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 4, 64)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"sync"
+
+	"sixDivides/bot"
+	"sixDivides/rules"
+)
+
+// config is one set of games, every seat played by the same policy
+type config struct {
+	policy string
+	width  int
+	height int
+	seats  []int
+}
+
+// gameResult is how a single game ended
+type gameResult struct {
+	// winner is the index of the winning player, or rules.NoWinner
+	winner int
+	// draw is set when the last pieces removed each other, stalemate when nobody could move
+	draw       bool
+	stalemate  bool
+	unfinished bool
+	turns      int
+	actions    int
+	// corners are where each player started, as a corner name
+	corners []string
+}
+
+type job struct {
+	config int
+	game   int
+	seed   int64
+}
+
+// simulate plays the games of every config on a number of workers, and returns the results of each config in order
+func simulate(configs []config, games int, maxActions int, workers int, seed int64) [][]gameResult {
+	results := make([][]gameResult, len(configs))
+	for i := range results {
+		results[i] = make([]gameResult, games)
+	}
+
+	jobs := make(chan job)
+	var wg sync.WaitGroup
+	for w := 0; w < max(workers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				// each game writes to its own slot, so no lock is needed
+				results[j.config][j.game] = playGame(configs[j.config], maxActions, j.seed)
+			}
+		}()
+	}
+
+	next := seed
+	for c := range configs {
+		for g := 0; g < games; g++ {
+			jobs <- job{config: c, game: g, seed: next}
+			next++
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// playGame plays one game to the end, or until it has gone on for maxActions
+func playGame(c config, maxActions int, seed int64) gameResult {
+	s := rules.NewGame(c.width, c.height, c.seats)
+	bots := make([]bot.Bot, len(s.Players))
+	for i := range bots {
+		// the policy was checked when the configs were made
+		bots[i], _ = bot.New(c.policy, seed*int64(len(bots))+int64(i))
+	}
+	defer func() {
+		for _, b := range bots {
+			bot.Close(b)
+		}
+	}()
+
+	result := gameResult{winner: rules.NoWinner, corners: make([]string, len(s.Players))}
+	for i, player := range s.Players {
+		result.corners[i] = cornerName(s.Board, player.StartingPosition)
+	}
+
+	for !s.GameOver && result.actions < maxActions {
+		n, _, err := rules.Apply(s, bots[s.Turn].NextMove(s))
+		if err != nil {
+			// the bots only pick legal moves, but end the turn rather than stop the whole run
+			n = rules.EndTurn(s)
+		}
+		s = n
+		result.actions++
+	}
+
+	result.turns = s.TurnsPlayed
+	switch {
+	case !s.GameOver:
+		result.unfinished = true
+	case s.Winner != rules.NoWinner:
+		result.winner = s.Winner
+	case len(s.Remaining()) == 0:
+		result.draw = true
+	default:
+		result.stalemate = true
+	}
+	return result
+}
+
+// cornerName is the corner of the board the position is nearest
+func cornerName(board rules.Board, p rules.Position) string {
+	name := "top"
+	if p.Y*2 > board.Height {
+		name = "bottom"
+	}
+	if p.X*2 > board.Width {
+		return name + "-right"
+	}
+	return name + "-left"
+}
@@ -0,0 +1,106 @@
+// Command sixtour runs a tournament between bots, round robin or swiss. Each
+// pairing plays a match of two player games, with the seats rotated so both
+// bots get every corner and go first equally often. Elo and Glicko ratings
+// are kept in a file across runs, and a leaderboard and crosstable are
+// printed at the end.
+//
+//	go run ./cmd/sixtour -players random,greedy,minimax-easy,mcts-easy
+//	go run ./cmd/sixtour -format swiss -rounds 4 -players "greedy,exec:./sixbot -kind greedy"
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"runtime"
+	"strings"
+
+	"sixDivides/bot"
+	"sixDivides/rules"
+)
+
+func main() {
+	players := flag.String("players", "random,greedy,minimax-easy,mcts-easy", "comma separated bots to enter, any of "+strings.Join(bot.Kinds, ", ")+", or "+bot.ExternalPrefix+"command for an external bot")
+	format := flag.String("format", formatRoundRobin, "tournament format, "+formatRoundRobin+" or "+formatSwiss)
+	rounds := flag.Int("rounds", 0, "rounds of a swiss tournament, 0 plays enough to find a winner")
+	games := flag.Int("games", len(seatRotation), "games in each match, a multiple of 4 rotates the seats evenly")
+	board := flag.String("board", "8x8", "board size, as columns x rows")
+	maxActions := flag.Int("max-actions", 5000, "actions after which a game is given up as a draw")
+	workers := flag.Int("workers", runtime.NumCPU(), "games played at the same time")
+	seed := flag.Int64("seed", 1, "seed for the first game, each game after uses the next one")
+	ratingsFile := flag.String("ratings", "ratings.json", "file the ratings are kept in across runs, empty to not keep them")
+	system := flag.String("system", systemElo, "rating the leaderboard is sorted by, "+systemElo+" or "+systemGlicko)
+	flag.Parse()
+
+	entrants, err := parsePlayers(*players)
+	if err != nil {
+		log.Fatal(err)
+	}
+	width, height, err := rules.ParseBoardSize(*board)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *games < 1 {
+		log.Fatal("a match needs at least one game")
+	}
+	if *system != systemElo && *system != systemGlicko {
+		log.Fatalf("unknown rating system %q, use %v or %v", *system, systemElo, systemGlicko)
+	}
+
+	ratings, err := loadRatings(*ratingsFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	t := newTournament(entrants, ratings, tournamentConfig{
+		games:      *games,
+		width:      width,
+		height:     height,
+		maxActions: *maxActions,
+		workers:    *workers,
+		seed:       *seed,
+	})
+
+	switch *format {
+	case formatRoundRobin:
+		t.playRoundRobin()
+	case formatSwiss:
+		if *rounds <= 0 {
+			*rounds = int(math.Ceil(math.Log2(float64(len(entrants)))))
+		}
+		t.playSwiss(*rounds)
+	default:
+		log.Fatalf("unknown format %q, use %v or %v", *format, formatRoundRobin, formatSwiss)
+	}
+
+	if err := saveRatings(*ratingsFile, ratings); err != nil {
+		log.Fatal(err)
+	}
+	writeLeaderboard(os.Stdout, t, *system)
+	fmt.Println()
+	writeCrosstable(os.Stdout, t, *system)
+}
+
+// parsePlayers checks each bot can be made, and that none is entered twice
+func parsePlayers(players string) ([]string, error) {
+	var entrants []string
+	seen := map[string]bool{}
+	for _, player := range strings.Split(players, ",") {
+		player = strings.TrimSpace(player)
+		if seen[player] {
+			return nil, fmt.Errorf("%q is entered twice", player)
+		}
+		seen[player] = true
+		b, err := bot.New(player, 0)
+		if err != nil {
+			return nil, err
+		}
+		bot.Close(b)
+		entrants = append(entrants, player)
+	}
+	if len(entrants) < 2 {
+		return nil, fmt.Errorf("a tournament needs at least 2 players")
+	}
+	return entrants, nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"sort"
+)
+
+// the -system options
+const (
+	systemElo    = "elo"
+	systemGlicko = "glicko"
+)
+
+const ratingsVersion = 1
+
+const (
+	// startRating is where a new player starts in both systems
+	startRating = 1500
+	// eloK is how far one game moves an Elo rating
+	eloK = 24
+	// startDeviation is the Glicko deviation of a new player, how unsure the rating is
+	startDeviation = 350
+	// minDeviation stops a rating that has had many games from never moving again
+	minDeviation = 30
+	// runDeviation is how much less sure a rating gets between runs, as the bots may have changed
+	runDeviation = 35
+)
+
+// ratingFile is the ratings of every player that has ever been in a tournament
+type ratingFile struct {
+	Version int                `json:"version"`
+	Players map[string]*rating `json:"players"`
+}
+
+// rating is a players ratings and their games over all runs
+type rating struct {
+	Elo    float64 `json:"elo"`
+	Glicko float64 `json:"glicko"`
+	// Deviation is the Glicko rating deviation
+	Deviation float64 `json:"deviation"`
+	Games     int     `json:"games"`
+	Wins      int     `json:"wins"`
+	Losses    int     `json:"losses"`
+	Draws     int     `json:"draws"`
+}
+
+func (r *rating) value(system string) float64 {
+	if system == systemGlicko {
+		return r.Glicko
+	}
+	return r.Elo
+}
+
+// loadRatings reads the ratings file, an empty name or a missing file starts with no ratings
+func loadRatings(name string) (*ratingFile, error) {
+	ratings := &ratingFile{Version: ratingsVersion, Players: map[string]*rating{}}
+	if name == "" {
+		return ratings, nil
+	}
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ratings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, ratings); err != nil {
+		return nil, fmt.Errorf("could not read ratings %v: %w", name, err)
+	}
+	if ratings.Version != ratingsVersion {
+		return nil, fmt.Errorf("unsupported ratings version %d in %v", ratings.Version, name)
+	}
+	if ratings.Players == nil {
+		ratings.Players = map[string]*rating{}
+	}
+	return ratings, nil
+}
+
+func saveRatings(name string, ratings *ratingFile) error {
+	if name == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(ratings, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0o644)
+}
+
+// get returns the players rating, adding a new one if they have never played
+func (f *ratingFile) get(player string) *rating {
+	r, ok := f.Players[player]
+	if !ok {
+		r = &rating{Elo: startRating, Glicko: startRating, Deviation: startDeviation}
+		f.Players[player] = r
+	}
+	return r
+}
+
+// startRun makes the returning players ratings less certain, as time has passed since they last played
+func (f *ratingFile) startRun(players []string) {
+	for _, player := range players {
+		r := f.get(player)
+		if r.Games > 0 {
+			r.Deviation = math.Min(math.Hypot(r.Deviation, runDeviation), startDeviation)
+		}
+	}
+}
+
+// record updates both players ratings after a game, score is what a scored
+func (f *ratingFile) record(a string, b string, score float64) {
+	ra, rb := f.get(a), f.get(b)
+	before := *ra
+
+	ra.Elo, rb.Elo = ra.Elo+eloK*(score-eloExpected(ra.Elo, rb.Elo)), rb.Elo+eloK*((1-score)-eloExpected(rb.Elo, ra.Elo))
+	ra.Glicko, ra.Deviation = glickoUpdate(ra.Glicko, ra.Deviation, rb.Glicko, rb.Deviation, score)
+	rb.Glicko, rb.Deviation = glickoUpdate(rb.Glicko, rb.Deviation, before.Glicko, before.Deviation, 1-score)
+
+	for _, result := range []struct {
+		r     *rating
+		score float64
+	}{{ra, score}, {rb, 1 - score}} {
+		result.r.Games++
+		switch result.score {
+		case 1:
+			result.r.Wins++
+		case 0:
+			result.r.Losses++
+		default:
+			result.r.Draws++
+		}
+	}
+}
+
+// eloExpected is the score a player rated a is expected to get against one rated b
+func eloExpected(a float64, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// glickoQ is the constant that turns ratings into the Glicko scale
+var glickoQ = math.Ln10 / 400
+
+// glickoG weights a game by how sure the opponents rating is
+func glickoG(deviation float64) float64 {
+	return 1 / math.Sqrt(1+3*glickoQ*glickoQ*deviation*deviation/(math.Pi*math.Pi))
+}
+
+// glickoUpdate is the players new rating and deviation after one game, treated as its own rating period
+func glickoUpdate(r float64, deviation float64, opponent float64, opponentDeviation float64, score float64) (float64, float64) {
+	g := glickoG(opponentDeviation)
+	expected := 1 / (1 + math.Pow(10, -g*(r-opponent)/400))
+	dSquared := 1 / (glickoQ * glickoQ * g * g * expected * (1 - expected))
+	precision := 1/(deviation*deviation) + 1/dSquared
+	r += glickoQ / precision * g * (score - expected)
+	return r, math.Max(math.Sqrt(1/precision), minDeviation)
+}
+
+// ranked is the names of the players sorted by the rating system, best first
+func (f *ratingFile) ranked(players []string, system string) []string {
+	sorted := append([]string(nil), players...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return f.get(sorted[i]).value(system) > f.get(sorted[j]).value(system)
+	})
+	return sorted
+}
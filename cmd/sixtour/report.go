@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// writeLeaderboard lists the players by their rating, with this tournaments score next to it
+func writeLeaderboard(w io.Writer, t *tournament, system string) error {
+	index := map[string]int{}
+	for i, player := range t.players {
+		index[player] = i
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%v, sorted by %v\n", t.description(), system)
+	fmt.Fprintln(tw, "#\tplayer\telo\tglicko\tpoints\tgames\tall runs\t")
+	for rank, player := range t.ratings.ranked(t.players, system) {
+		r := t.ratings.get(player)
+		i := index[player]
+		games := strconv.Itoa(t.gamesPlayed(i))
+		if t.byes[i] > 0 {
+			// a bye is worth the points of winning a whole match
+			games += fmt.Sprintf(" and %v bye", t.byes[i])
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%.0f\t%.0f ±%.0f\t%v\t%v\t%v won, %v lost, %v drawn\t\n",
+			rank+1, player, r.Elo, r.Glicko, r.Deviation, formatPoints(t.points[i]), games, r.Wins, r.Losses, r.Draws)
+	}
+	return tw.Flush()
+}
+
+// writeCrosstable shows the points each player, in order of the standings,
+// scored against each other player, the columns are numbered as the rows
+func writeCrosstable(w io.Writer, t *tournament, system string) error {
+	order := t.standings(system)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := []string{"", "player"}
+	for column := range order {
+		header = append(header, strconv.Itoa(column+1))
+	}
+	fmt.Fprintln(tw, strings.Join(append(header, "points"), "\t")+"\t")
+
+	for row, a := range order {
+		cells := []string{strconv.Itoa(row+1) + ".", t.players[a]}
+		for _, b := range order {
+			switch {
+			case a == b:
+				cells = append(cells, "x")
+			case t.played[a][b] == 0:
+				cells = append(cells, ".")
+			default:
+				cells = append(cells, fmt.Sprintf("%v/%v", formatPoints(t.against[a][b]), t.played[a][b]))
+			}
+		}
+		cells = append(cells, formatPoints(t.points[a]))
+		fmt.Fprintln(tw, strings.Join(cells, "\t")+"\t")
+	}
+	return tw.Flush()
+}
+
+// formatPoints writes half points as .5 and whole points without a fraction
+func formatPoints(points float64) string {
+	return strconv.FormatFloat(points, 'f', -1, 64)
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"sixDivides/bot"
+	"sixDivides/rules"
+)
+
+// the -format options
+const (
+	formatRoundRobin = "roundrobin"
+	formatSwiss      = "swiss"
+)
+
+// seatRotation is the seats of each game in a match, as for rules.NewGame.
+// Player 1 goes first, and every game after the first swaps which bot is
+// player 1, so over four games each bot starts in every corner once and goes
+// first twice.
+var seatRotation = [][]int{
+	{1, -1, -1, 2},
+	{-1, 1, 2, -1},
+	{2, -1, -1, 1},
+	{-1, 2, 1, -1},
+}
+
+type tournamentConfig struct {
+	games      int
+	width      int
+	height     int
+	maxActions int
+	workers    int
+	seed       int64
+}
+
+// tournament is the players, their results against each other and their ratings
+type tournament struct {
+	config  tournamentConfig
+	format  string
+	players []string
+	ratings *ratingFile
+	// points are each players score in this tournament, a win is 1 and a draw 1/2
+	points []float64
+	// against is the points each player scored against each other player
+	against [][]float64
+	played  [][]int
+	byes    []int
+	rounds  int
+	// nextSeed is the seed of the next game, each game gets its own
+	nextSeed int64
+}
+
+func newTournament(players []string, ratings *ratingFile, config tournamentConfig) *tournament {
+	t := &tournament{
+		config:   config,
+		players:  players,
+		ratings:  ratings,
+		points:   make([]float64, len(players)),
+		against:  make([][]float64, len(players)),
+		played:   make([][]int, len(players)),
+		byes:     make([]int, len(players)),
+		nextSeed: config.seed,
+	}
+	for i := range players {
+		t.against[i] = make([]float64, len(players))
+		t.played[i] = make([]int, len(players))
+	}
+	ratings.startRun(players)
+	return t
+}
+
+// pairing is a match between two players, by index
+type pairing struct {
+	a int
+	b int
+}
+
+// game is one game of a match, aFirst is set when a is player 1
+type game struct {
+	pairing
+	seats  []int
+	aFirst bool
+	seed   int64
+	// score is what a scored, 1 for a win, 1/2 for a draw or unfinished game
+	score float64
+}
+
+// playRoundRobin plays every player against every other player once
+func (t *tournament) playRoundRobin() {
+	t.format = "round robin"
+	var pairings []pairing
+	for a := range t.players {
+		for b := a + 1; b < len(t.players); b++ {
+			pairings = append(pairings, pairing{a: a, b: b})
+		}
+	}
+	t.playRound(pairings)
+}
+
+// playSwiss plays the rounds, each time pairing players on the same score who have not met yet
+func (t *tournament) playSwiss(rounds int) {
+	t.format = formatSwiss
+	for r := 0; r < rounds; r++ {
+		t.playRound(t.swissPairings())
+	}
+}
+
+// swissPairings pairs each player, from the top of the standings down, with
+// the next one they have not played. With an odd number of players the lowest
+// player who has not had one gets a bye, which scores as winning the match.
+func (t *tournament) swissPairings() []pairing {
+	order := t.standings(systemElo)
+	if len(order)%2 == 1 {
+		bye := len(order) - 1
+		for i := len(order) - 1; i >= 0; i-- {
+			if t.byes[order[i]] < t.byes[order[bye]] {
+				bye = i
+			}
+		}
+		t.byes[order[bye]]++
+		t.points[order[bye]] += float64(t.config.games)
+		order = append(order[:bye:bye], order[bye+1:]...)
+	}
+
+	var pairings []pairing
+	paired := make([]bool, len(order))
+	for i, a := range order {
+		if paired[i] {
+			continue
+		}
+		// a rematch is only played when everyone left has already met a
+		opponent := -1
+		for j := i + 1; j < len(order); j++ {
+			if paired[j] {
+				continue
+			}
+			if opponent == -1 {
+				opponent = j
+			}
+			if t.played[a][order[j]] == 0 {
+				opponent = j
+				break
+			}
+		}
+		paired[i], paired[opponent] = true, true
+		pairings = append(pairings, pairing{a: a, b: order[opponent]})
+	}
+	return pairings
+}
+
+// playRound plays the games of every match on a number of workers, then
+// counts them and updates the ratings in the order they were scheduled, so a
+// run with the same seed always ends the same way
+func (t *tournament) playRound(pairings []pairing) {
+	t.rounds++
+	var games []game
+	for _, p := range pairings {
+		for g := 0; g < t.config.games; g++ {
+			games = append(games, game{pairing: p, seats: seatRotation[g%len(seatRotation)], aFirst: g%2 == 0, seed: t.nextSeed})
+			t.nextSeed++
+		}
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < max(t.config.workers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				// each game writes to its own slot, so no lock is needed
+				games[i].score = t.play(games[i])
+			}
+		}()
+	}
+	for i := range games {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, g := range games {
+		t.points[g.a] += g.score
+		t.points[g.b] += 1 - g.score
+		t.against[g.a][g.b] += g.score
+		t.against[g.b][g.a] += 1 - g.score
+		t.played[g.a][g.b]++
+		t.played[g.b][g.a]++
+		t.ratings.record(t.players[g.a], t.players[g.b], g.score)
+	}
+}
+
+// play plays one game to the end, and returns what player a scored
+func (t *tournament) play(g game) float64 {
+	s := rules.NewGame(t.config.width, t.config.height, g.seats)
+	first, second := g.a, g.b
+	if !g.aFirst {
+		first, second = g.b, g.a
+	}
+	bots := make([]bot.Bot, 2)
+	for i, player := range []int{first, second} {
+		// the players were checked before the tournament started
+		bots[i], _ = bot.New(t.players[player], g.seed*2+int64(i))
+	}
+	defer func() {
+		for _, b := range bots {
+			bot.Close(b)
+		}
+	}()
+
+	for actions := 0; !s.GameOver && actions < t.config.maxActions; actions++ {
+		n, _, err := rules.Apply(s, bots[s.Turn].NextMove(s))
+		if err != nil {
+			// the bots only pick legal moves, but end the turn rather than stop the whole tournament
+			n = rules.EndTurn(s)
+		}
+		s = n
+	}
+
+	switch {
+	case !s.GameOver || s.Winner == rules.NoWinner:
+		return 0.5
+	case (s.Winner == 0) == g.aFirst:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// standings is the players by index, most points first, with the rating breaking ties
+func (t *tournament) standings(system string) []int {
+	order := make([]int, len(t.players))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if t.points[a] != t.points[b] {
+			return t.points[a] > t.points[b]
+		}
+		return t.ratings.get(t.players[a]).value(system) > t.ratings.get(t.players[b]).value(system)
+	})
+	return order
+}
+
+// gamesPlayed is how many games the player had in this tournament, not counting byes
+func (t *tournament) gamesPlayed(player int) int {
+	games := 0
+	for _, n := range t.played[player] {
+		games += n
+	}
+	return games
+}
+
+func (t *tournament) description() string {
+	rounds := ""
+	if t.format == formatSwiss {
+		rounds = fmt.Sprintf(" of %v rounds", t.rounds)
+	}
+	return fmt.Sprintf("%v%v, %v players, %v game matches on %vx%v", t.format, rounds, len(t.players), t.config.games, t.config.width+1, t.config.height+1)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// action is something the player can do, whatever it is bound to. The keys,
+// the mouse, touches and gamepads all end up in handleAction.
+type action int
+
+const (
+	actionCursorUp action = iota
+	actionCursorDown
+	actionCursorLeft
+	actionCursorRight
+	// actionSelect picks a piece, a button or a menue row
+	actionSelect
+	// actionEndTurn ends the turn, and is the second button of the menues, such as human or bot
+	actionEndTurn
+	// actionOpenMenu opens the menue while playing, and goes back out of the menues
+	actionOpenMenu
+	// actionTarget lets the cursor pick any tile for the selected piece to go to, see targeting
+	actionTarget
+	actionUndo
+	actionRedo
+	// actionReplayStart and actionReplayEnd jump to either end of a replay
+	actionReplayStart
+	actionReplayEnd
+	actionCount
+)
+
+// actionNames are how the actions are written in the settings file
+var actionNames = [actionCount]string{
+	"CursorUp", "CursorDown", "CursorLeft", "CursorRight",
+	"Select", "EndTurn", "OpenMenu", "Target", "Undo", "Redo", "ReplayStart", "ReplayEnd",
+}
+
+// actionLabels are how the actions are shown on the key bindings screen
+var actionLabels = [actionCount]string{
+	"Up", "Down", "Left", "Right",
+	"Select", "End turn", "Menue / back", "Go to tile", "Undo", "Redo", "Replay start", "Replay end",
+}
+
+// keyBinding is a key, and the modifiers that have to be held with it
+type keyBinding struct {
+	key   ebiten.Key
+	ctrl  bool
+	shift bool
+}
+
+func (b keyBinding) String() string {
+	name := b.key.String()
+	if b.shift {
+		name = "Shift+" + name
+	}
+	if b.ctrl {
+		name = "Ctrl+" + name
+	}
+	return name
+}
+
+// parseKeyBinding reads a binding as written by String, such as Ctrl+Shift+Z
+func parseKeyBinding(s string) (keyBinding, error) {
+	var b keyBinding
+	parts := strings.Split(s, "+")
+	for _, modifier := range parts[:len(parts)-1] {
+		switch modifier {
+		case "Ctrl":
+			b.ctrl = true
+		case "Shift":
+			b.shift = true
+		default:
+			return b, fmt.Errorf("unknown modifier %q in %q", modifier, s)
+		}
+	}
+	if err := b.key.UnmarshalText([]byte(parts[len(parts)-1])); err != nil {
+		return b, err
+	}
+	return b, nil
+}
+
+// pressed is true while the key is held down. A binding with modifiers needs
+// exactly those held, and one without only works while control is not held,
+// so ctrl+z does not also count as z.
+func (b keyBinding) pressed() bool {
+	if !ebiten.IsKeyPressed(b.key) {
+		return false
+	}
+	if b.ctrl || b.shift {
+		return isControlPressed() == b.ctrl && ebiten.IsKeyPressed(ebiten.KeyShift) == b.shift
+	}
+	return !isControlPressed()
+}
+
+// keyBindings are the bindings of each action
+type keyBindings [actionCount][]keyBinding
+
+// keyLayouts are the keys that move the cursor up, down, left and right in each layout
+var keyLayouts = map[string][4]ebiten.Key{
+	"Arrows": {ebiten.KeyArrowUp, ebiten.KeyArrowDown, ebiten.KeyArrowLeft, ebiten.KeyArrowRight},
+	"WASD":   {ebiten.KeyW, ebiten.KeyS, ebiten.KeyA, ebiten.KeyD},
+	"HJKL":   {ebiten.KeyK, ebiten.KeyJ, ebiten.KeyH, ebiten.KeyL},
+}
+
+// defaultBindings are the bindings of the key layout, before any have been changed
+func defaultBindings(layout string) keyBindings {
+	var bindings keyBindings
+	for i, key := range keyLayouts[layout] {
+		bindings[actionCursorUp+action(i)] = []keyBinding{{key: key}}
+	}
+	bindings[actionSelect] = []keyBinding{{key: ebiten.KeySpace}}
+	bindings[actionEndTurn] = []keyBinding{{key: ebiten.KeyEnter}}
+	bindings[actionOpenMenu] = []keyBinding{{key: ebiten.KeyEscape}}
+	bindings[actionTarget] = []keyBinding{{key: ebiten.KeyT}}
+	bindings[actionUndo] = []keyBinding{{key: ebiten.KeyBackspace}, {key: ebiten.KeyZ, ctrl: true}}
+	bindings[actionRedo] = []keyBinding{{key: ebiten.KeyY, ctrl: true}, {key: ebiten.KeyZ, ctrl: true, shift: true}}
+	bindings[actionReplayStart] = []keyBinding{{key: ebiten.KeyHome}}
+	bindings[actionReplayEnd] = []keyBinding{{key: ebiten.KeyEnd}}
+	return bindings
+}
+
+// bindingsFor are the bindings of the settings key layout, with the actions
+// bound to other keys on the key bindings screen replacing them
+func bindingsFor(settings Settings) keyBindings {
+	bindings := defaultBindings(settings.KeyLayout)
+	for a, name := range actionNames {
+		keys, ok := settings.KeyBindings[name]
+		if !ok {
+			continue
+		}
+		var custom []keyBinding
+		for _, key := range keys {
+			b, err := parseKeyBinding(key)
+			if err != nil {
+				log.Printf("ignoring the %v key binding: %v", name, err)
+				continue
+			}
+			custom = append(custom, b)
+		}
+		if len(custom) > 0 {
+			bindings[a] = custom
+		}
+	}
+	return bindings
+}
+
+// actionPressed is true while any of the keys bound to the action are held down
+func (g *Game) actionPressed(a action) bool {
+	for _, b := range g.bindings[a] {
+		if b.pressed() {
+			return true
+		}
+	}
+	return false
+}
+
+// tickActions does each action whose keys were pressed this frame
+func tickActions(g *Game) {
+	for a := action(0); a < actionCount; a++ {
+		if g.actionPressed(a) {
+			// only once for each press, holding the key down does not repeat it
+			if !g.actionStates[a] {
+				g.actionStates[a] = true
+				g.handleAction(a)
+			}
+		} else {
+			g.actionStates[a] = false
+		}
+	}
+}
+
+// holdActions marks the actions being held as done, so a key that was just
+// bound does not do its new action until it is pressed again
+func holdActions(g *Game) {
+	for a := action(0); a < actionCount; a++ {
+		g.actionStates[a] = g.actionPressed(a)
+	}
+}
+
+// actionKeyName is the first key bound to the action, for the instructions
+// on screen, with the arrow keys shortened to their direction
+func actionKeyName(g *Game, a action) string {
+	if len(g.bindings[a]) == 0 {
+		return "none"
+	}
+	return strings.ToLower(strings.TrimPrefix(g.bindings[a][0].String(), "Arrow"))
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"sixDivides/rules"
+)
+
+// the board size row on the new game screen sits under the four sections, so
+// it carries on their numbering for uiNewGameSectionHighlighted
+const (
+	newGameColumnsCell = 4
+	newGameRowsCell    = 5
+)
+
+// squareBoardSizes are the board sizes the settings screen cycles through, as
+// used by rules.CreateBoard. The new game screen can also change them one side at a time.
+func squareBoardSizes() []rules.Position {
+	var sizes []rules.Position
+	for size := rules.MinBoardSize; size <= rules.MaxBoardSize; size++ {
+		sizes = append(sizes, rules.Position{X: size - 1, Y: size - 1})
+	}
+	return sizes
+}
+
+// onBoardSizeRow is true when the columns or rows of the new game board are highlighted
+func onBoardSizeRow(g *Game) bool {
+	return !g.uiStartNewGameButton && (g.uiNewGameSectionHighlighted == newGameColumnsCell || g.uiNewGameSectionHighlighted == newGameRowsCell)
+}
+
+// changeBoardSize adds direction to the number of columns or rows of new
+// games, going round from the largest to the smallest size
+func changeBoardSize(g *Game, cell int, direction int) {
+	count := rules.MaxBoardSize - rules.MinBoardSize + 1
+	side := &g.settings.BoardWidth
+	if cell == newGameRowsCell {
+		side = &g.settings.BoardHeight
+	}
+	*side = cycleOption(count, *side+1-rules.MinBoardSize, direction) + rules.MinBoardSize - 1
+	// the board is remembered for next time
+	if err := saveSettings(g.settings); err != nil {
+		log.Printf("could not save settings: %v", err)
+	}
+}
+
+// boardSizeLabels describe the board of the new game, or the room while in one
+func boardSizeLabels(g *Game) (string, string) {
+	width, height := g.settings.BoardWidth, g.settings.BoardHeight
+	if inLobby(g) {
+		width, height = g.network.lobby.Width, g.network.lobby.Height
+	}
+	return fmt.Sprintf("Columns: %v", width+1), fmt.Sprintf("Rows: %v", height+1)
+}
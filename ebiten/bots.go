@@ -0,0 +1,162 @@
+package main
+
+import (
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"sixDivides/bot"
+	"sixDivides/rules"
+)
+
+// botActionDelay is how long a bot waits before each action, so the other players can follow what it does
+const botActionDelay = 600 * time.Millisecond
+
+// seatControllers are who can play in a new game section, "" is a human at this
+// computer, followed by the built in bots and the external bots from the settings
+func seatControllers(g *Game) []string {
+	controllers := append([]string{""}, bot.Kinds...)
+	for _, command := range g.settings.ExternalBots {
+		controllers = append(controllers, bot.ExternalPrefix+command)
+	}
+	return controllers
+}
+
+// createBots makes the bots for a new game. controllers is the controller of
+// each section, and sections the player number sat in it as for rules.NewGame.
+func createBots(g *Game, sections []int, controllers []string) {
+	kinds := make([]string, len(g.state.Players))
+	for i, p := range sections {
+		if p != -1 && p <= len(kinds) {
+			kinds[p-1] = controllers[i]
+		}
+	}
+	setBots(g, kinds)
+}
+
+// setBots makes a bot of the named kind for each player, "" leaves the player to a human
+func setBots(g *Game, kinds []string) {
+	for _, b := range g.bots {
+		// an external bot can take a while to quit, and may still be thinking
+		go bot.Close(b)
+	}
+	g.bots = make([]bot.Bot, len(g.state.Players))
+	g.botTicks = 0
+	seed := time.Now().UnixNano()
+	for i, kind := range kinds {
+		if kind == "" || i >= len(g.bots) {
+			continue
+		}
+		b, err := bot.New(kind, seed+int64(i))
+		if err != nil {
+			log.Printf("%v will be played by a human: %v", g.state.Players[i].Name, err)
+			continue
+		}
+		g.bots[i] = b
+	}
+}
+
+// botKinds is the kind of bot playing each player, "" for humans
+func botKinds(g *Game) []string {
+	kinds := make([]string, len(g.state.Players))
+	for i := range kinds {
+		if i < len(g.bots) && g.bots[i] != nil {
+			kinds[i] = g.bots[i].Name()
+		}
+	}
+	return kinds
+}
+
+// currentBot is the bot playing the current player, or nil when it is a humans turn
+func currentBot(g *Game) bot.Bot {
+	if g.state.GameOver || g.state.Turn >= len(g.bots) {
+		return nil
+	}
+	return g.bots[g.state.Turn]
+}
+
+func isBotTurn(g *Game) bool {
+	return currentBot(g) != nil
+}
+
+// tickBots is called every frame. Once the delay has passed it asks the bot for
+// its next action, which it thinks about in the background so the game keeps
+// drawing, and plays the action when it is ready.
+func tickBots(g *Game) {
+	if g.botMove != nil {
+		// the bot is thinking, any answer waits until the game is being played again
+		if g.gameState != 0 {
+			return
+		}
+		select {
+		case m := <-g.botMove:
+			g.botMove = nil
+			if !reflect.DeepEqual(g.botAsked, g.state) {
+				// the game was changed by an undo, load or new game while the bot was thinking, so ask again
+				return
+			}
+			playBotMove(g, currentBot(g), m)
+		default:
+		}
+		return
+	}
+
+	b := currentBot(g)
+	if g.gameState != 0 || b == nil {
+		g.botTicks = 0
+		return
+	}
+
+	g.botTicks++
+	if g.botTicks < int(botActionDelay.Seconds()*float64(ebiten.TPS())) {
+		return
+	}
+	g.botTicks = 0
+
+	// a bot is only asked for one move at a time, so the next request waits for this one to finish
+	g.botAsked = g.state
+	g.botMove = make(chan rules.Move, 1)
+	go func(b bot.Bot, s rules.State, result chan<- rules.Move) {
+		result <- b.NextMove(s)
+	}(b, g.state.Clone(), g.botMove)
+}
+
+// botThinking reports if the current player is a bot working out its next action
+func botThinking(g *Game) bool {
+	return g.botMove != nil
+}
+
+// playBotMove plays the action the bot picked
+func playBotMove(g *Game, b bot.Bot, m rules.Move) {
+	if b == nil {
+		return
+	}
+	if err := applyMove(g, m); err != nil {
+		// a bot should only pick legal moves, but make sure the game can not get stuck on one
+		log.Printf("%v bot picked an invalid move %v: %v", b.Name(), m, err)
+		if err := applyMove(g, rules.EndTurnMove); err != nil {
+			log.Printf("could not end the bots turn: %v", err)
+		}
+	}
+}
+
+// cycleSeatController changes the new game section between a human and each kind of bot
+func cycleSeatController(g *Game, section int) {
+	controllers := seatControllers(g)
+	current := 0
+	for i, controller := range controllers {
+		if controller == g.uiNewGameSectionBot[section] {
+			current = i
+		}
+	}
+	g.uiNewGameSectionBot[section] = controllers[cycleOption(len(controllers), current, 1)]
+}
+
+// controllerLabel is how a seat controller is shown to the players
+func controllerLabel(controller string) string {
+	if controller == "" {
+		return "human"
+	}
+	return controller + " bot"
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"sixDivides/online"
+)
+
+// broadcastDelayOptions are the settings choices for broadcasting, in seconds behind the game, -1 is off
+var broadcastDelayOptions = []int{-1, 0, 10, 30, 60}
+
+// broadcaster publishes the game played at this computer to the server, for
+// others to watch in the browser. Like networkGame it runs in its own
+// goroutines, and Update passes it the recording whenever it changes.
+type broadcaster struct {
+	records chan string
+	codes   chan string
+	cancel  context.CancelFunc
+	delay   int
+	// code is the broadcast code the watchers pick the game by
+	code string
+	// changed is set when the game has changed since it was last sent
+	changed bool
+}
+
+// startBroadcast connects to the server, and keeps broadcasting until stopBroadcast
+func startBroadcast(g *Game) {
+	stopBroadcast(g)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &broadcaster{
+		records: make(chan string, 1),
+		codes:   make(chan string, 1),
+		cancel:  cancel,
+		delay:   g.settings.BroadcastDelay,
+		changed: true,
+	}
+	g.broadcast = b
+	go b.run(ctx, g.settings.ServerURL, g.settings.PlayerName)
+}
+
+func stopBroadcast(g *Game) {
+	if g.broadcast == nil {
+		return
+	}
+	g.broadcast.cancel()
+	g.broadcast = nil
+}
+
+// broadcastChanged marks the game to be sent again on the next frame
+func broadcastChanged(g *Game) {
+	if g.broadcast != nil {
+		g.broadcast.changed = true
+	}
+}
+
+// tickBroadcast is called every frame, to send the game when it has changed
+func tickBroadcast(g *Game) {
+	b := g.broadcast
+	if b == nil {
+		return
+	}
+	select {
+	case b.code = <-b.codes:
+		log.Printf("broadcasting as game %v", b.code)
+	default:
+	}
+
+	// online games are already on the server, and games without a recording can not be shown
+	if !b.changed || g.network != nil || g.recordName == "" {
+		return
+	}
+	data, err := g.record.MarshalText()
+	if err != nil {
+		log.Printf("could not broadcast the game: %v", err)
+		return
+	}
+	b.changed = false
+
+	// only the latest game matters, so replace any the connection has not sent yet
+	select {
+	case <-b.records:
+	default:
+	}
+	b.records <- string(data)
+}
+
+// run sends each recording to the server, connecting again whenever the connection is lost.
+// Each connection is a new broadcast, with a new code. It stops once the server
+// refuses the game, as it would only refuse it again.
+func (b *broadcaster) run(ctx context.Context, url string, name string) {
+	var last string
+	for {
+		if last == "" {
+			select {
+			case last = <-b.records:
+			case <-ctx.Done():
+				return
+			}
+		}
+		var err error
+		if last, err = b.connection(ctx, url, name, last); err != nil {
+			log.Printf("stopped broadcasting: %v", err)
+			return
+		}
+
+		select {
+		case <-time.After(reconnectDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// connection broadcasts until the connection is lost or cancelled, and returns
+// the last recording. The error is why the server refused the game, when it did.
+func (b *broadcaster) connection(ctx context.Context, url string, name string, last string) (string, error) {
+	conn, _, err := websocket.Dial(ctx, url, nil)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("could not connect to %v to broadcast: %v", url, err)
+		}
+		return last, nil
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(online.MaxMessageSize)
+
+	msg := online.ClientMessage{Type: online.TypeBroadcast, Name: name, Delay: b.delay, Record: last}
+	if err := wsjson.Write(ctx, conn, msg); err != nil {
+		return last, nil
+	}
+
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	refused := make(chan error, 1)
+	go func() {
+		defer cancel()
+		for {
+			var msg online.ServerMessage
+			if err := wsjson.Read(connCtx, conn, &msg); err != nil {
+				if websocket.CloseStatus(err) == websocket.StatusMessageTooBig {
+					refused <- errors.New("the game is too long for the server")
+				}
+				return
+			}
+			switch msg.Type {
+			case online.TypeBroadcasting:
+				select {
+				case <-b.codes:
+				default:
+				}
+				b.codes <- msg.Room
+			case online.TypeError:
+				refused <- errors.New(msg.Error)
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case last = <-b.records:
+			if err := wsjson.Write(connCtx, conn, online.ClientMessage{Type: online.TypeBroadcast, Record: last}); err != nil {
+				// the reader stops with the connection, and says if the server refused the game
+				conn.CloseNow()
+			}
+		case <-connCtx.Done():
+			if ctx.Err() != nil {
+				conn.Close(websocket.StatusNormalClosure, "")
+			}
+			select {
+			case err := <-refused:
+				return last, err
+			default:
+				return last, nil
+			}
+		}
+	}
+}
@@ -27,7 +27,7 @@ for ((i=0; i<length; i++)); do
 
     echo "  Platform: $GOOS, Architecture: $GOARCH, Extension: $BIN_EXT"
 
-    go build -o "builds/$GOOS-$GOARCH$BIN_EXT" main.go
+    go build -o "builds/$GOOS-$GOARCH$BIN_EXT" .
 done
 
 echo "Finished Building!"
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"sixDivides/rules"
+)
+
+// gamepadActions are the actions of the buttons of a gamepad in the standard
+// layout. B is not here, as it backs out or deselects depending on the screen.
+var gamepadActions = []struct {
+	button ebiten.StandardGamepadButton
+	action action
+}{
+	{ebiten.StandardGamepadButtonLeftTop, actionCursorUp},
+	{ebiten.StandardGamepadButtonLeftBottom, actionCursorDown},
+	{ebiten.StandardGamepadButtonLeftLeft, actionCursorLeft},
+	{ebiten.StandardGamepadButtonLeftRight, actionCursorRight},
+	{ebiten.StandardGamepadButtonRightBottom, actionSelect},
+	{ebiten.StandardGamepadButtonRightLeft, actionTarget},
+	{ebiten.StandardGamepadButtonCenterRight, actionOpenMenu},
+	{ebiten.StandardGamepadButtonFrontTopRight, actionEndTurn},
+	{ebiten.StandardGamepadButtonFrontTopLeft, actionUndo},
+}
+
+// how far the left stick has to be pushed before it counts as the d-pad
+const gamepadStickDeadZone = 0.5
+
+// tickGamepads is called every frame, and handles the gamepads the same way
+// as tickPointer does the mouse, by doing the actions of the buttons through
+// handleAction. With more than one gamepad in a hot seat game each plays a seat,
+// see gamepadSeat, and only the menues are shared.
+func tickGamepads(g *Game) {
+	g.uiGamepads = ebiten.AppendGamepadIDs(g.uiGamepads[:0])
+	// the ids go up as gamepads are connected, so the first one plugged in plays the first seat
+	sort.Slice(g.uiGamepads, func(i, j int) bool { return g.uiGamepads[i] < g.uiGamepads[j] })
+	if g.uiGamepadStick == nil {
+		g.uiGamepadStick = make(map[ebiten.GamepadID]action)
+	}
+
+	for n, id := range g.uiGamepads {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		for _, pad := range gamepadActions {
+			if inpututil.IsStandardGamepadButtonJustPressed(id, pad.button) {
+				pressGamepad(g, n, pad.action)
+			}
+		}
+		if inpututil.IsStandardGamepadButtonJustPressed(id, ebiten.StandardGamepadButtonRightRight) {
+			cancelGamepad(g, n)
+		}
+
+		// the stick moves the cursor as it is pushed over, and again only once it has been let go
+		a, pushed := gamepadStick(id)
+		held, wasPushed := g.uiGamepadStick[id]
+		if pushed && (!wasPushed || held != a) {
+			pressGamepad(g, n, a)
+		}
+		if pushed {
+			g.uiGamepadStick[id] = a
+		} else {
+			delete(g.uiGamepadStick, id)
+		}
+	}
+}
+
+// gamepadStick is the cursor action of the way the left stick is pushed furthest
+func gamepadStick(id ebiten.GamepadID) (action, bool) {
+	x := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+	y := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+	switch {
+	case max(x, -x, y, -y) < gamepadStickDeadZone:
+		return 0, false
+	case x >= y && x >= -y:
+		return actionCursorRight, true
+	case -x >= y && -x >= -y:
+		return actionCursorLeft, true
+	case y > 0:
+		return actionCursorDown, true
+	}
+	return actionCursorUp, true
+}
+
+// gamepadSeat is the player the nth gamepad plays on the board. One gamepad,
+// or a game online, plays every seat this computer does. Otherwise the
+// gamepads take the human seats in order, and any left over only work the menues.
+func gamepadSeat(g *Game, n int) (int, bool) {
+	if len(g.uiGamepads) < 2 || g.network != nil {
+		return g.state.Turn, true
+	}
+	seat := 0
+	for i := range g.state.Players {
+		if i < len(g.bots) && g.bots[i] != nil {
+			continue
+		}
+		if seat == n {
+			return i, true
+		}
+		seat++
+	}
+	return 0, false
+}
+
+// gamepadTurn is true when the nth gamepad can play the board right now
+func gamepadTurn(g *Game, n int) bool {
+	seat, ok := gamepadSeat(g, n)
+	return ok && seat == g.state.Turn
+}
+
+// pressGamepad does the action for the nth gamepad. On the board only the
+// gamepad of the player whose turn it is does anything, except for opening the menue.
+func pressGamepad(g *Game, n int, a action) {
+	if g.gameState == 0 && a != actionOpenMenu && !gamepadTurn(g, n) {
+		return
+	}
+	g.handleAction(a)
+}
+
+// cancelGamepad is the B button, it stops picking a tile to go to or deselects
+// the selected piece on the board,
+// says no to starting a new game, and backs out of the other menues
+func cancelGamepad(g *Game, n int) {
+	switch g.gameState {
+	case 0:
+		if gamepadTurn(g, n) && targeting(g) {
+			stopTargeting(g)
+		} else if gamepadTurn(g, n) && isLocalTurn(g) {
+			g.SelectedTile = rules.NoPosition
+		}
+	case 1:
+		// the menue is closed by esc, but B should not open it again
+		g.gameState = 0
+	case 2:
+		g.uiNewGameConfirmation = false
+		g.handleAction(actionSelect)
+	default:
+		g.handleAction(actionOpenMenu)
+	}
+}
+
+// gamepadMessage says which gamepad plays the current turn, when several are sharing the game
+func gamepadMessage(g *Game) (string, bool) {
+	if len(g.uiGamepads) < 2 || g.network != nil || !isLocalTurn(g) {
+		return "", false
+	}
+	for n := range g.uiGamepads {
+		if gamepadTurn(g, n) {
+			return fmt.Sprintf("Gamepad %v plays %v: 'A' select, 'B' deselect, 'R1' end turn", n+1, g.state.CurrentPlayer().Name), true
+		}
+	}
+	return fmt.Sprintf("No gamepad plays %v, use the keys", g.state.CurrentPlayer().Name), true
+}
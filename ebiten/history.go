@@ -0,0 +1,87 @@
+package main
+
+import (
+	"log"
+
+	"sixDivides/rules"
+)
+
+// historyEntry is a snapshot of the game and cursor from before an action.
+// rules.Apply never changes the state it is given, so the snapshots can be kept as is.
+type historyEntry struct {
+	state           rules.State
+	highlightedTile rules.Position
+	selectedTile    rules.Position
+	recordedMoves   []rules.Move
+}
+
+// history holds the undo and redo stacks for hot-seat play
+type history struct {
+	undo []historyEntry
+	redo []historyEntry
+}
+
+func (g *Game) snapshot() historyEntry {
+	// cap the moves at their length, so appending after an undo copies them rather than overwriting the redo entries
+	moves := g.record.Moves[:len(g.record.Moves):len(g.record.Moves)]
+	return historyEntry{state: g.state, highlightedTile: g.HighlightedTile, selectedTile: g.SelectedTile, recordedMoves: moves}
+}
+
+func (g *Game) restore(entry historyEntry) {
+	g.state = entry.state
+	g.HighlightedTile = entry.highlightedTile
+	g.SelectedTile = entry.selectedTile
+	g.InvalidTile = rules.NoPosition
+	g.record.Moves = entry.recordedMoves
+	broadcastChanged(g)
+}
+
+// recordHistory is called with the snapshot from before an action once the action has been made.
+// A new action means the undone actions can no longer be redone.
+func recordHistory(g *Game, before historyEntry) {
+	g.history.undo = append(g.history.undo, before)
+	g.history.redo = nil
+
+	// unless undo is unrestricted, only the current players turn can be taken back
+	if !g.settings.UnrestrictedUndo && g.state.TurnsPlayed != before.state.TurnsPlayed {
+		clearHistory(g)
+	}
+}
+
+func clearHistory(g *Game) {
+	g.history.undo = nil
+	g.history.redo = nil
+}
+
+func undo(g *Game) {
+	// online games can not be taken back, the other players have already seen the moves
+	if g.network != nil {
+		log.Println("can not undo online")
+		return
+	}
+	if len(g.history.undo) == 0 {
+		log.Println("nothing to undo")
+		return
+	}
+	last := len(g.history.undo) - 1
+	g.history.redo = append(g.history.redo, g.snapshot())
+	g.restore(g.history.undo[last])
+	g.history.undo = g.history.undo[:last]
+	log.Printf("undo, %v has %v actions", g.state.CurrentPlayer().Name, g.state.CurrentPlayer().Actions)
+}
+
+func redo(g *Game) {
+	if g.network != nil {
+		log.Println("can not redo online")
+		return
+	}
+	if len(g.history.redo) == 0 {
+		log.Println("nothing to redo")
+		return
+	}
+	last := len(g.history.redo) - 1
+	g.history.undo = append(g.history.undo, g.snapshot())
+	g.restore(g.history.redo[last])
+	g.history.redo = g.history.redo[:last]
+	log.Printf("redo, %v has %v actions", g.state.CurrentPlayer().Name, g.state.CurrentPlayer().Actions)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// key bindings screen rows, a row for each action followed by these
+const (
+	bindingsRowReset = int(actionCount) + iota
+	bindingsRowBack
+	bindingsRowCount
+)
+
+// openKeyBindings shows the key bindings screen. The key layout picked in the
+// settings is put to use first, so the keys shown are the ones that work.
+func openKeyBindings(g *Game) {
+	g.bindings = bindingsFor(g.settings)
+	g.uiBindingsSelected = 0
+	g.uiBindingsWaiting = false
+	g.uiBindingsMessage = ""
+	g.gameState = 10
+}
+
+// pressBindingsRow waits for the new key of the selected action, or does what the other rows are for
+func pressBindingsRow(g *Game) {
+	switch g.uiBindingsSelected {
+	case bindingsRowReset:
+		g.settings.KeyBindings = nil
+		storeBindings(g)
+		g.uiBindingsMessage = fmt.Sprintf("Back to the %v keys", g.settings.KeyLayout)
+	case bindingsRowBack:
+		g.gameState = 5
+	default:
+		g.uiBindingsWaiting = true
+		g.uiBindingsMessage = fmt.Sprintf("Press the new key for %v, 'esc' to keep the old one", actionLabels[g.uiBindingsSelected])
+	}
+}
+
+// resetBinding puts the selected action back on the keys of the key layout
+func resetBinding(g *Game) {
+	if g.uiBindingsSelected >= int(actionCount) {
+		return
+	}
+	delete(g.settings.KeyBindings, actionNames[g.uiBindingsSelected])
+	storeBindings(g)
+	g.uiBindingsMessage = fmt.Sprintf("%v is back on its %v key", actionLabels[g.uiBindingsSelected], g.settings.KeyLayout)
+}
+
+// captureBinding is called every frame while waiting for a new key, and binds
+// the first key pressed to the selected action. An action that had the key
+// before is given the old keys of the selected one, so both still have a key.
+func captureBinding(g *Game) {
+	// a click or tap keeps the old keys too, for when there is no esc
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) || len(inpututil.AppendJustPressedTouchIDs(nil)) > 0 {
+		g.uiBindingsWaiting = false
+		g.uiBindingsMessage = ""
+		holdActions(g)
+		return
+	}
+	for _, key := range inpututil.AppendJustPressedKeys(nil) {
+		switch key {
+		case ebiten.KeyControl, ebiten.KeyControlLeft, ebiten.KeyControlRight,
+			ebiten.KeyShift, ebiten.KeyShiftLeft, ebiten.KeyShiftRight,
+			ebiten.KeyAlt, ebiten.KeyAltLeft, ebiten.KeyAltRight,
+			ebiten.KeyMeta, ebiten.KeyMetaLeft, ebiten.KeyMetaRight:
+			// held with the key, not bound on their own
+			continue
+		case ebiten.KeyEscape:
+			g.uiBindingsWaiting = false
+			g.uiBindingsMessage = ""
+			holdActions(g)
+			return
+		}
+
+		a := action(g.uiBindingsSelected)
+		b := keyBinding{key: key, ctrl: isControlPressed(), shift: ebiten.IsKeyPressed(ebiten.KeyShift)}
+		g.uiBindingsMessage = fmt.Sprintf("%v is now %v", actionLabels[a], b)
+		for other := action(0); other < actionCount; other++ {
+			if other == a {
+				continue
+			}
+			var kept []keyBinding
+			for _, binding := range g.bindings[other] {
+				if binding != b {
+					kept = append(kept, binding)
+				}
+			}
+			if len(kept) == len(g.bindings[other]) {
+				continue
+			}
+			if len(kept) == 0 {
+				kept = g.bindings[a]
+			}
+			setBinding(g, other, kept)
+			g.uiBindingsMessage = fmt.Sprintf("%v is now %v, and %v is %v", actionLabels[a], b, actionLabels[other], bindingNames(kept))
+		}
+		setBinding(g, a, []keyBinding{b})
+		storeBindings(g)
+		g.uiBindingsWaiting = false
+		return
+	}
+}
+
+// setBinding keeps the keys of the action in the settings
+func setBinding(g *Game, a action, bindings []keyBinding) {
+	if g.settings.KeyBindings == nil {
+		g.settings.KeyBindings = make(map[string][]string)
+	}
+	var keys []string
+	for _, b := range bindings {
+		keys = append(keys, b.String())
+	}
+	g.settings.KeyBindings[actionNames[a]] = keys
+}
+
+// storeBindings uses the changed bindings straight away, and saves them for next time
+func storeBindings(g *Game) {
+	g.bindings = bindingsFor(g.settings)
+	holdActions(g)
+	if err := saveSettings(g.settings); err != nil {
+		log.Printf("could not save settings: %v", err)
+	}
+}
+
+func bindingNames(bindings []keyBinding) string {
+	var names []string
+	for _, b := range bindings {
+		names = append(names, b.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+// bindingsLabels describes the keys of each action
+func bindingsLabels(g *Game) []string {
+	labels := make([]string, bindingsRowCount)
+	for a := action(0); a < actionCount; a++ {
+		labels[a] = fmt.Sprintf("%v: %v", actionLabels[a], bindingNames(g.bindings[a]))
+		if _, ok := g.settings.KeyBindings[actionNames[a]]; ok {
+			labels[a] += " *"
+		}
+	}
+	if g.uiBindingsWaiting {
+		labels[g.uiBindingsSelected] = fmt.Sprintf("%v: ...", actionLabels[g.uiBindingsSelected])
+	}
+	labels[bindingsRowReset] = fmt.Sprintf("Reset all to %v", g.settings.KeyLayout)
+	labels[bindingsRowBack] = "Back"
+	return labels
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"image"
+)
+
+// where the buttons of each screen are drawn, so Draw and the mouse and touch
+// input agree on what is under the pointer
+
+// the pause menue is a column of buttons inside a box
+const (
+	pauseMenueBorder       = 50
+	pauseMenueButtonBorder = 20
+	pauseMenueButtonHeight = 55
+)
+
+func pauseMenueButton(g *Game, i int) image.Rectangle {
+	x := pauseMenueBorder + pauseMenueButtonBorder
+	y := pauseMenueBorder + pauseMenueButtonBorder + (pauseMenueButtonBorder+pauseMenueButtonHeight)*i
+	width := g.screenSize.X - (pauseMenueBorder * 2) - (pauseMenueButtonBorder * 2)
+	return image.Rect(x, y, x+width, y+pauseMenueButtonHeight)
+}
+
+// the new game confirmation is a box in the middle of the screen with no and yes buttons
+const (
+	confirmWidth        = 400
+	confirmHeight       = 140
+	confirmButtonBorder = 25
+)
+
+func confirmBox(g *Game) image.Rectangle {
+	x := (g.screenSize.X / 2) - (confirmWidth / 2)
+	y := (g.screenSize.Y / 2) - (confirmHeight / 2)
+	return image.Rect(x, y, x+confirmWidth, y+confirmHeight)
+}
+
+func confirmButton(g *Game, yes bool) image.Rectangle {
+	box := confirmBox(g)
+	width := (confirmWidth - (confirmButtonBorder * 3)) / 2
+	x := box.Min.X + confirmButtonBorder
+	if yes {
+		x += width + confirmButtonBorder
+	}
+	y := box.Min.Y + confirmButtonBorder
+	return image.Rect(x, y, x+width, y+confirmHeight-(confirmButtonBorder*2))
+}
+
+// the new game screen has the four corner sections, the board size row under
+// them, a message box and the start button along the bottom
+const (
+	newGameBorder           = 50
+	newGameStartAreaHeight  = 150
+	newGameMessageBoxHeight = 150
+	newGameBoardRowHeight   = 44
+)
+
+func newGameSectionSize(g *Game) (int, int) {
+	width := (g.screenSize.X / 2) - (newGameBorder / 2) - newGameBorder
+	height := ((g.screenSize.Y - newGameStartAreaHeight - newGameMessageBoxHeight) / 2) - (newGameBorder / 2) - newGameBorder
+	return width, height
+}
+
+// newGameMessageBoxY is the top of the board size row, with the messages under it
+func newGameMessageBoxY(g *Game) int {
+	_, height := newGameSectionSize(g)
+	return (newGameBorder * (2 + 1)) + (height * 2)
+}
+
+// newGameSection is where a corner section is drawn, or one of the board size
+// cells for newGameColumnsCell and newGameRowsCell
+func newGameSection(g *Game, index int) image.Rectangle {
+	width, height := newGameSectionSize(g)
+	column := index % 2
+	x := (newGameBorder * (column + 1)) + (width * column)
+	if index >= newGameColumnsCell {
+		y := newGameMessageBoxY(g)
+		return image.Rect(x, y, x+width, y+newGameBoardRowHeight)
+	}
+	row := index / 2
+	y := (newGameBorder * (row + 1)) + (height * row)
+	return image.Rect(x, y, x+width, y+height)
+}
+
+func newGameStartButton(g *Game) image.Rectangle {
+	y := g.screenSize.Y - newGameStartAreaHeight
+	return image.Rect(newGameBorder, y, g.screenSize.X-newGameBorder, y+newGameStartAreaHeight-newGameBorder)
+}
+
+// list menues have a title, a row for each label and a message under them
+const (
+	listMenueBorder       = 50
+	listMenueButtonBorder = 20
+	listMenueTitleHeight  = 36
+)
+
+func listMenueBox(g *Game) image.Rectangle {
+	return image.Rect(listMenueBorder, listMenueBorder, g.screenSize.X-listMenueBorder, g.screenSize.Y-listMenueBorder)
+}
+
+// listMenueRow is where row i of a list of count rows is drawn. Long lists
+// get shorter rows closer together, so they still fit above the message.
+func listMenueRow(g *Game, count int, i int) image.Rectangle {
+	height, gap := 60, listMenueButtonBorder
+	if fits := (g.screenSize.Y - (listMenueBorder * 2) - listMenueTitleHeight - (listMenueButtonBorder * 3) - 40) / max(count, 1); fits < height+gap {
+		gap = max(fits/4, 4)
+		height = max(fits-gap, 24)
+	}
+	x := listMenueBorder + listMenueButtonBorder
+	y := listMenueBorder + listMenueButtonBorder + listMenueTitleHeight + listMenueButtonBorder + (gap+height)*i
+	width := g.screenSize.X - (listMenueBorder * 2) - (listMenueButtonBorder * 2)
+	return image.Rect(x, y, x+width, y+height)
+}
+
+// the play screen has buttons for the mouse and touch to the right of the status under the board
+const (
+	playButtonWidth  = 95
+	playButtonHeight = 50
+	playButtonBorder = 10
+)
+
+// playButton is the end turn button when menue is false, or the one that opens the menue
+func playButton(g *Game, menue bool) image.Rectangle {
+	x := g.screenSize.X - (playButtonWidth+playButtonBorder)*2
+	if menue {
+		x += playButtonWidth + playButtonBorder
+	}
+	y := (g.state.Board.Height+1)*g.tileSize + playButtonBorder
+	return image.Rect(x, y, x+playButtonWidth, y+playButtonHeight)
+}
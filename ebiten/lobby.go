@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"sixDivides/online"
+)
+
+// online menue rows
+const (
+	onlineRowCreate = iota
+	onlineRowJoin
+	onlineRowRejoin
+	onlineRowBack
+	onlineRowCount
+)
+
+// openOnlineMenue shows the choice of creating a room, or joining one with its code
+func openOnlineMenue(g *Game) {
+	g.uiOnlineSelected = onlineRowCreate
+	g.uiOnlineMessage = ""
+	g.uiOnlineSession = loadOnlineSession()
+	g.gameState = 9
+}
+
+func onlineLabels(g *Game) []string {
+	labels := make([]string, onlineRowCount)
+	labels[onlineRowCreate] = fmt.Sprintf("Create Room (%vx%v board)", g.settings.BoardWidth+1, g.settings.BoardHeight+1)
+	labels[onlineRowJoin] = "Join Room: " + g.uiOnlineCode + strings.Repeat("_", online.CodeLength-len(g.uiOnlineCode))
+	labels[onlineRowRejoin] = "Rejoin Last Game: none"
+	if session := g.uiOnlineSession; session.Room != "" && session.ServerURL == g.settings.ServerURL {
+		labels[onlineRowRejoin] = "Rejoin Last Game: room " + session.Room
+	}
+	labels[onlineRowBack] = "Back"
+	return labels
+}
+
+// typeJoinCode adds any digits typed this frame to the join code, and
+// backspace takes the last one off whatever undo is bound to
+func typeJoinCode(g *Game) {
+	if g.uiOnlineSelected != onlineRowJoin {
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(g.uiOnlineCode) > 0 {
+		g.uiOnlineCode = g.uiOnlineCode[:len(g.uiOnlineCode)-1]
+	}
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if r >= '0' && r <= '9' && len(g.uiOnlineCode) < online.CodeLength {
+			g.uiOnlineCode += string(r)
+		}
+	}
+}
+
+// pressOnlineRow does what the selected row of the online menue is for
+func pressOnlineRow(g *Game) {
+	switch g.uiOnlineSelected {
+	case onlineRowCreate:
+		log.Println("creating a room")
+		g.uiOnlineMessage = "Connecting..."
+		connectOnline(g, online.ClientMessage{Type: online.TypeCreate, Width: g.settings.BoardWidth, Height: g.settings.BoardHeight})
+	case onlineRowJoin:
+		if len(g.uiOnlineCode) != online.CodeLength {
+			g.uiOnlineMessage = fmt.Sprintf("Type the %v digit code of the room", online.CodeLength)
+			return
+		}
+		log.Printf("joining room %v", g.uiOnlineCode)
+		g.uiOnlineMessage = "Connecting..."
+		connectOnline(g, online.ClientMessage{Type: online.TypeJoin, Room: g.uiOnlineCode})
+	case onlineRowRejoin:
+		session := g.uiOnlineSession
+		if session.Room == "" || session.ServerURL != g.settings.ServerURL {
+			g.uiOnlineMessage = "There is no game on this server to rejoin"
+			return
+		}
+		log.Printf("rejoining room %v", session.Room)
+		g.uiOnlineMessage = "Connecting..."
+		connectOnline(g, online.ClientMessage{Type: online.TypeJoin, Room: session.Room, Token: session.Token})
+	case onlineRowBack:
+		g.gameState = 1
+	}
+}
+
+// inLobby is true while the new game screen is showing a room on the server
+func inLobby(g *Game) bool {
+	return g.network != nil && g.network.lobby != nil
+}
+
+// pressLobby sits in or stands up from the highlighted section, or starts the game for the host
+func pressLobby(g *Game) {
+	lobby := g.network.lobby
+	var err error
+	if onBoardSizeRow(g) {
+		// the board was picked when the room was made
+		return
+	} else if g.uiStartNewGameButton {
+		if !lobby.Host {
+			return
+		}
+		err = sendOnline(g, online.ClientMessage{Type: online.TypeStart})
+	} else if lobby.You == g.uiNewGameSectionHighlighted {
+		err = sendOnline(g, online.ClientMessage{Type: online.TypeSeat, Section: online.NoSection})
+	} else if lobby.Seats[g.uiNewGameSectionHighlighted].Name == "" {
+		err = sendOnline(g, online.ClientMessage{Type: online.TypeSeat, Section: g.uiNewGameSectionHighlighted})
+	}
+	if err != nil {
+		g.network.status = err.Error()
+	}
+}
+
+// toggleReady tells the others in the room if you are ready to play
+func toggleReady(g *Game) {
+	lobby := g.network.lobby
+	if lobby.You == online.NoSection {
+		return
+	}
+	if err := sendOnline(g, online.ClientMessage{Type: online.TypeReady, Ready: !lobby.Seats[lobby.You].Ready}); err != nil {
+		g.network.status = err.Error()
+	}
+}
+
+// lobbySection describes who is sat in the section of the room
+func lobbySection(g *Game, section int) (taken bool, title string, subtitle string) {
+	lobby := g.network.lobby
+	seat := lobby.Seats[section]
+	if seat.Name == "" {
+		return false, "", ""
+	}
+
+	title = seat.Name
+	if lobby.You == section {
+		title += " (you)"
+	}
+	subtitle = "not ready"
+	if seat.Ready {
+		subtitle = "ready"
+	}
+	if seat.Host {
+		subtitle = "host"
+	}
+	return true, title, subtitle
+}
+
+// lobbyMessages are the instructions under the sections while in a room
+func lobbyMessages(g *Game) []string {
+	status := g.network.status
+	if g.network.lobby.Watching > 0 {
+		status = fmt.Sprintf("%v watching, %v", g.network.lobby.Watching, status)
+	}
+	return []string{
+		"Room code " + g.network.room,
+		"Space sit/stand, enter ready",
+		status,
+	}
+}
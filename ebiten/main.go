@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"image"
 	"image/color"
 	"log"
 
@@ -10,824 +11,653 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/examples/resources/fonts"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
+	"sixDivides/bot"
+	"sixDivides/notation"
+	"sixDivides/rules"
 )
 
-type Player struct {
-	Color            color.Color
-	Name             string
-	Pieces           []Piece
-	Actions          int
-	PlayerIndex      int
-	StartingPosition Position
-}
-
-type Position struct {
-	X int
-	Y int
-}
-
-type Piece struct {
-	Color       color.Color
-	Value       int
-	PlayerIndex int
-	Position    Position
-}
-
-type Tile struct {
-	Position Position
-	Piece    Piece
-}
-
-type Board struct {
-	Tiles    [][]Tile
-	Width    int
-	Height   int
-	TileSize int
-}
-
 // Game represents the game state
 type Game struct {
-	keyStates                   map[ebiten.Key]bool
-	board                       Board
-	players                     []Player
-	turn                        int
-	HighlightedTile             Position
-	SelectedTile                Position
-	InvalidTile                 Position
-	GameOver                    bool
+	actionStates                [actionCount]bool
+	state                       rules.State
+	tileSize                    int
+	HighlightedTile             rules.Position
+	SelectedTile                rules.Position
+	InvalidTile                 rules.Position
 	gameState                   int
 	uiMenueSelectedButton       int
 	uiMenueButtonNumber         int
 	uiNewGameConfirmation       bool
 	uiNewGameSectionPlayer      []int
 	uiNewGameSectionHighlighted int
+	uiNewGameSectionBot         []string
 	uiStartNewGameButton        bool
-	screenSize                  Position
+	uiSaveSlotSelected          int
+	uiSaveSlotSaving            bool
+	uiSaveSlotLabels            []string
+	uiSaveSlotMessage           string
+	uiSettingsSelected          int
+	uiReplayNames               []string
+	uiReplaySelected            int
+	uiReplayMessage             string
+	uiOnlineSelected            int
+	uiOnlineCode                string
+	uiOnlineMessage             string
+	uiOnlineSession             onlineSession
+	uiPointer                   image.Point
+	uiPointerUsed               bool
+	uiGamepads                  []ebiten.GamepadID
+	uiGamepadStick              map[ebiten.GamepadID]action
+	uiTargeting                 bool
+	uiTargetFrom                rules.Position
+	uiTargetTile                rules.Position
+	uiBindingsSelected          int
+	uiBindingsWaiting           bool
+	uiBindingsMessage           string
+	history                     history
+	record                      notation.Record
+	recordName                  string
+	replay                      replayViewer
+	bots                        []bot.Bot
+	botTicks                    int
+	botMove                     chan rules.Move
+	botAsked                    rules.State
+	network                     *networkGame
+	broadcast                   *broadcaster
+	settings                    Settings
+	bindings                    keyBindings
+	screenSize                  rules.Position
 }
 
-// createBoard creates a new board with the given width and height
-func createBoard(width int, height int, tileSize int) Board {
-	board := Board{Width: width, Height: height}
-	board.TileSize = tileSize
-
-	// make the the tiles array and account for the extra row/column for the boarder
-	tiles := make([][]Tile, width+1)
-	for x := 0; x <= width; x++ {
-		tiles[x] = make([]Tile, height+1)
-		for y := 0; y <= height; y++ {
-			tile := Tile{Position: Position{X: x, Y: y}, Piece: Piece{}}
-			tiles[x][y] = tile
-		}
+func handleTileMove(g *Game, xOffset, yOffset int) {
+	// the board is left alone while a bot or someone online is playing
+	if !isLocalTurn(g) {
+		return
 	}
-	board.Tiles = tiles
 
-	return board
-}
+	// check if the selected tile is set, if so move the piece on the tile, to the tile above it
+	if g.SelectedTile == rules.NoPosition {
+		// set the new highlighted tile to true and the previous one to false
+		g.HighlightedTile.X += xOffset
+		g.HighlightedTile.Y += yOffset
+		log.Printf("highlighter is now at %d, %d", g.HighlightedTile.X, g.HighlightedTile.Y)
+		return
+	}
 
-func createPlayers(playerPositions []int) []Player {
+	// get the target tiles position, and let the rules decide what the piece does there
+	target := rules.Position{X: g.HighlightedTile.X + xOffset, Y: g.HighlightedTile.Y + yOffset}
+	if err := playMove(g, rules.Move{From: g.SelectedTile, To: target}); err != nil {
+		log.Printf("invalid move to %d, %d: %v", target.X, target.Y, err)
+		g.InvalidTile = target
+	}
+}
 
-	numberOfPlayers := 0
-	for _, section := range playerPositions {
-		if section != -1 {
-			numberOfPlayers++
-		}
+// applyMove plays the move for the current player, and keeps the cursor, undo
+// history and recording of the game up to date
+func applyMove(g *Game, m rules.Move) error {
+	newState, result, err := rules.Apply(g.state, m)
+	if err != nil {
+		return err
+	}
+	before := g.snapshot()
+	g.state = newState
+
+	// update the user with the new highlighted and selected tiles
+	g.HighlightedTile = result.Highlighted
+	g.SelectedTile = result.Selected
+	m.Kind = result.Kind
+	recordMove(g, m, result)
+	recordHistory(g, before)
+	broadcastChanged(g)
+
+	if result.TurnEnded {
+		log.Printf("End Turn, it is now %v's turn", g.state.CurrentPlayer().Name)
+		focusCurrentPlayer(g)
 	}
+	showResultsIfOver(g)
+	return nil
+}
 
-	var players []Player = make([]Player, numberOfPlayers)
-	var startingPosition Position
-	var playerColor color.Color
-	var playerName string
-	for i, p := range playerPositions {
-		if p != -1 {
-			switch i {
-			case 0:
-				playerName = fmt.Sprintf("Player%v", p)
-				playerColor = color.RGBA{0x00, 0x00, 0xff, 0xff}
-				startingPosition = Position{1, 1}
-			case 1:
-				playerName = fmt.Sprintf("Player%v", p)
-				playerColor = color.RGBA{0xff, 0x00, 0x00, 0xff}
-				startingPosition = Position{1, 6}
-			case 2:
-				playerName = fmt.Sprintf("Player%v", p)
-				playerColor = color.RGBA{0x00, 0xff, 0xff, 0xff}
-				startingPosition = Position{6, 1}
-			case 3:
-				playerName = fmt.Sprintf("Player%v", p)
-				playerColor = color.RGBA{0xff, 0x00, 0xff, 0xff}
-				startingPosition = Position{6, 6}
-			}
-			players[p-1] = newPlayer(playerName, playerColor, startingPosition, p-1)
-		}
+// playMove plays a move made at this computer, online it is sent to the server
+// and only played once the server sends it back
+func playMove(g *Game, m rules.Move) error {
+	if g.network != nil {
+		return sendOnlineMove(g, m)
 	}
+	return applyMove(g, m)
+}
 
-	return players
+// isLocalTurn is true when the current player is played at this computer
+func isLocalTurn(g *Game) bool {
+	if isBotTurn(g) {
+		return false
+	}
+	return g.network == nil || g.network.player == g.state.Turn
 }
 
-func newPlayer(name string, playerColor color.Color, startingPosition Position, playerGameIndex int) Player {
-	player := Player{
-		Color:            playerColor,
-		Name:             name,
-		StartingPosition: startingPosition,
-		PlayerIndex:      playerGameIndex,
-		Actions:          0,
-		Pieces:           make([]Piece, 1),
+// focusCurrentPlayer clears up from the previous players turn and puts the
+// highlighter on the oldest piece of the player whose turn it now is
+func focusCurrentPlayer(g *Game) {
+	g.SelectedTile = rules.NoPosition
+
+	player := g.state.CurrentPlayer()
+	if len(player.Pieces) > 0 {
+		g.HighlightedTile = player.Pieces[0].Position
 	}
 
-	startingPiece := Piece{Color: playerColor, Value: 6, PlayerIndex: playerGameIndex, Position: startingPosition}
-	player.Pieces[0] = startingPiece
-	return player
+	// printf the current players name and number of actions left and number of pieces they have
+	log.Printf("Player %s starts their turn with %d actions and %d pieces", player.Name, player.Actions, len(player.Pieces))
 }
 
-func handleTileMove(g *Game, xOffset, yOffset int) {
-	// check if the selected tile is set, if so move the piece on the tile, to the tile above it
-	if g.SelectedTile.X == -1 && g.SelectedTile.Y == -1 {
-		// set the new highlighted tile to true and the previous one to false
-		g.HighlightedTile.X += xOffset
-		g.HighlightedTile.Y += yOffset
-		log.Printf("highlighter is now at %d, %d", g.HighlightedTile.X, g.HighlightedTile.Y)
-	} else {
-		// move the piece on the selected tile to the tile above it
-		var noPiece Piece = Piece{}
+// Update proceeds the game state. Update is called every frame (1/60[s] by default).
+func (g *Game) Update() error {
+	if g.gameState == 8 {
+		tickReplay(g)
+	}
+	tickBots(g)
+	tickOnline(g)
+	tickBroadcast(g)
+	if g.gameState == 9 {
+		typeJoinCode(g)
+	}
+	if g.gameState == 10 && g.uiBindingsWaiting {
+		// the next key pressed is being bound, rather than doing anything
+		captureBinding(g)
+		return nil
+	}
+	tickPointer(g)
+	tickGamepads(g)
+	tickActions(g)
 
-		// get the target tiles position
-		targetX, targetY := g.HighlightedTile.X+xOffset, g.HighlightedTile.Y+yOffset
-		selectedX, selectedY := g.SelectedTile.X, g.SelectedTile.Y
+	return nil
+}
 
-		if g.board.Tiles[targetX][targetY].Piece == noPiece {
+// handleAction does what the action is for in the current game state, for
+// keys pressed this frame. The mouse, touches and gamepads act through it too.
+func (g *Game) handleAction(a action) {
+	if g.gameState == 0 && targeting(g) && handleTargetAction(g, a) {
+		return
+	}
 
-			if g.board.Tiles[selectedX][selectedY].Piece.Value == 6 {
-				// create a piece on the new tile of value 1
-				newPiece := Piece{Color: g.players[g.turn].Color, Value: 1, PlayerIndex: g.players[g.turn].PlayerIndex, Position: Position{X: targetX, Y: targetY}}
+	switch a {
+	case actionOpenMenu:
+		log.Println("esc")
+
+		// menue is game state 1, and can only be shown when game is running in state 0
+		if g.gameState == 0 {
+			// menue state
+			g.gameState = 1
+		} else if g.gameState == 1 {
+			// play state
+			g.gameState = 0
+		} else if g.gameState == 4 {
+			// back out of the save slot picker to the menue
+			g.gameState = 1
+		} else if g.gameState == 5 {
+			// leaving settings keeps the changes
+			closeSettings(g)
+		} else if g.gameState == 6 {
+			// results, go to the menue
+			g.gameState = 1
+		} else if g.gameState == 7 {
+			// back out of the replay picker to the menue
+			g.gameState = 1
+		} else if g.gameState == 8 {
+			// stop watching the replay and pick another
+			g.replay.autoplay = false
+			g.gameState = 7
+		} else if g.gameState == 9 {
+			// back out of the online menue
+			g.gameState = 1
+		} else if g.gameState == 3 && inLobby(g) {
+			// leave the room
+			disconnectOnline(g)
+			g.gameState = 1
+		} else if g.gameState == 10 {
+			// back out of the key bindings to the settings
+			g.gameState = 5
+		}
+
+	case actionEndTurn:
+		log.Println("enter")
+		//next players turn and reset if all players have moved
 
-				// add newpiece to the players pieces array
-				g.players[g.turn].Pieces = append(g.players[g.turn].Pieces, newPiece)
+		if g.gameState == 0 && isLocalTurn(g) {
+			if err := playMove(g, rules.EndTurnMove); err != nil {
+				log.Printf("could not end turn: %v", err)
+			}
+		} else if g.gameState == 3 {
+			// switch the highlighted section between a human and the bots
+			if inLobby(g) {
+				toggleReady(g)
+			} else if onBoardSizeRow(g) {
+				changeBoardSize(g, g.uiNewGameSectionHighlighted, -1)
+			} else if !g.uiStartNewGameButton && g.uiNewGameSectionPlayer[g.uiNewGameSectionHighlighted] != -1 {
+				cycleSeatController(g, g.uiNewGameSectionHighlighted)
+			}
+		} else if g.gameState == 8 {
+			// change the replay autoplay speed
+			g.replay.speed = (g.replay.speed + 1) % len(replaySpeeds)
+		} else if g.gameState == 10 {
+			// put the action back on the key of the layout
+			resetBinding(g)
+		}
 
-				// add the new piece to the board
-				usePlayerAction(g)
+	case actionSelect:
+		log.Println("space")
+		// The SelectedTile already highlighted, deselect it, else set
+		if g.gameState == 0 && isLocalTurn(g) {
+			if g.SelectedTile.X == -1 && g.SelectedTile.Y == -1 {
+				// is deselected, so automatically set
+
+				// get the pice one the selected tile, and see if belongs to current player
+				piece, ok := g.state.PieceAt(g.HighlightedTile)
+				if ok && piece.PlayerIndex == g.state.CurrentPlayer().PlayerIndex {
+					// todo, only select if the active players peice. else flash red
+					log.Println("\tSelected tile")
+					g.SelectedTile = g.HighlightedTile
+				} else {
+					// log piece at tile
+					log.Printf("\tNot belonging to current player")
+				}
 			} else {
-				// selected piece owned by the current player wants to move, and tile is empty
-
-				// move the position of the piece for the player that is currently selected
-				selectedPlayerId := g.board.Tiles[selectedX][selectedY].Piece.PlayerIndex
-				for i, piece := range g.players[selectedPlayerId].Pieces {
-					if piece.Position.X == selectedX && piece.Position.Y == selectedY {
-						g.players[selectedPlayerId].Pieces[i].Position = Position{targetX, targetY}
-						break
+				// is selected, check if selecting same tile, to deselect it
+				if g.SelectedTile == g.HighlightedTile {
+					g.SelectedTile = rules.NoPosition
+				} else {
+					// is selected, different tile so go there along the shortest path
+					if err := goToTile(g, g.HighlightedTile); err != nil {
+						log.Printf("can not go to %d, %d: %v", g.HighlightedTile.X, g.HighlightedTile.Y, err)
+						g.InvalidTile = g.HighlightedTile
+						g.SelectedTile = rules.NoPosition
 					}
 				}
-
-				g.HighlightedTile.X = targetX
-				g.HighlightedTile.Y = targetY
-
-				g.SelectedTile = g.HighlightedTile
-				usePlayerAction(g)
 			}
-
-		} else {
-			// there is a piece on the target tile
-
-			// check if the piece on the target tile is owned by the current player
-			if g.board.Tiles[targetX][targetY].Piece.PlayerIndex == g.players[g.turn].PlayerIndex {
-				// target tile is owned by the current player, and there is a piece on the selected tile
-				if g.board.Tiles[selectedX][selectedY].Piece.Value == 6 {
-					// selected piece is outpost and max value
-					if g.board.Tiles[targetX][targetY].Piece.Value < 6 {
-						// add one to the target pice value
-						targetPiece := g.board.Tiles[targetX][targetY].Piece
-						targetPlayerId := targetPiece.PlayerIndex
-						for i, piece := range g.players[targetPlayerId].Pieces {
-							if piece.Position.X == targetX && piece.Position.Y == targetY {
-								//update the player with the new value
-								g.players[targetPlayerId].Pieces[i].Value++
-								break
-							}
-						}
-						usePlayerAction(g)
-					} else {
-						// Can not add more than 6 to a picece
-						g.InvalidTile = Position{targetX, targetY}
-					}
+		} else if g.gameState == 1 {
+			// pause menue
+			switch g.uiMenueSelectedButton {
+			case 0:
+				log.Println("Resume")
+				// resume play state
+				g.gameState = 0
+			case 1:
+				log.Println("New Game")
+				// confirmation to make new game
+				g.gameState = 2
+			case 2:
+				log.Println("Online")
+				// create or join a room on the server, or leave it to carry on locally
+				if g.network == nil {
+					openOnlineMenue(g)
 				} else {
-					// piece is unit, and not max value, is trying to move to a tile that already has a piece on it owned by the current player
-
-					combinedValue := g.board.Tiles[targetX][targetY].Piece.Value + g.board.Tiles[selectedX][selectedY].Piece.Value
-
-					switch combinedValue {
-					case 0, 1:
-						// error, should not be able to make value 0 or 1 by combining two pieces
-						log.Fatalf("error: invalid value when combining own pieces: %v\n", combinedValue)
-						g.InvalidTile = Position{targetX, targetY}
-					case 2, 3, 4, 5, 6:
-						// combine the two pieces and remove the selected piece from the board and players pieces array
-
-						// find and update the target tile with the new value
-						targetPlayerId := g.board.Tiles[targetX][targetY].Piece.PlayerIndex
-						for i, piece := range g.players[targetPlayerId].Pieces {
-							if piece.Position.X == targetX && piece.Position.Y == targetY {
-								g.players[targetPlayerId].Pieces[i].Value = combinedValue
-								break // ensures that when the piece is removed, the last itteration will not cause an out of range exception
-							}
-						}
-						// now remove the selected piece from the board
-						selectedPlayerId := g.board.Tiles[selectedX][selectedY].Piece.PlayerIndex
-						for i, piece := range g.players[selectedPlayerId].Pieces {
-							if piece.Position.X == selectedX && piece.Position.Y == selectedY {
-								g.players[selectedPlayerId].Pieces = append(g.players[selectedPlayerId].Pieces[:i], g.players[selectedPlayerId].Pieces[i+1:]...)
-								break // ensures that when the piece is removed, the last itteration will not cause an out of range exception
-							}
-						}
-
-						// update the user with the new highlighted and selected tiles
-						g.HighlightedTile = Position{targetX, targetY}
-						g.SelectedTile = g.HighlightedTile
-
-						usePlayerAction(g)
-					case 7, 8, 9, 10, 11:
-
-						// only combine pieces where the targert pices, is no max value
-						if g.board.Tiles[targetX][targetY].Piece.Value < 6 {
-							// set the target tile to a value of 6, and set the selected piece to a value of newValue-6
-
-							targetPlayerId := g.board.Tiles[targetX][targetY].Piece.PlayerIndex
-							for i, piece := range g.players[targetPlayerId].Pieces {
-								if piece.Position.X == targetX && piece.Position.Y == targetY {
-									g.players[targetPlayerId].Pieces[i].Value = 6
-									break // ensures that when the piece is removed, the last itteration will not cause an out of range exception
-								}
-							}
-
-							// new set the selected pieces value to the remaining value
-							selectedPlayerId := g.board.Tiles[selectedX][selectedY].Piece.PlayerIndex
-							for i, piece := range g.players[selectedPlayerId].Pieces {
-								if piece.Position.X == selectedX && piece.Position.Y == selectedY {
-									g.players[selectedPlayerId].Pieces[i].Value = combinedValue - 6
-									break // ensures that when the piece is removed, the last itteration will not cause an out of range exception
-								}
-							}
-
-							usePlayerAction(g)
-						} else {
-							g.InvalidTile = Position{targetX, targetY}
-						}
-					case 12:
-						// invalid move, as both pices are at the maximum value
-						g.InvalidTile = Position{targetX, targetY}
-					default:
-						// error, should not be able to make a value greater than 12, of negative values, by combining two pieces
-						log.Fatalf("error: invalid value when combining own pieces: %v\n", combinedValue)
-						g.InvalidTile = Position{targetX, targetY}
-					}
-
+					disconnectOnline(g)
+					g.gameState = 0
+				}
+			case 3:
+				log.Println("Load")
+				openSaveSlotPicker(g, false)
+			case 4:
+				log.Println("Settings")
+				g.uiSettingsSelected = 0
+				g.gameState = 5
+			case 5:
+				log.Println("Save")
+				openSaveSlotPicker(g, true)
+			case 6:
+				log.Println("Replays")
+				openReplayPicker(g)
+			case 7:
+				log.Println("Exit")
+			}
+		} else if g.gameState == 4 {
+			// save slot picker
+			if g.uiSaveSlotSaving {
+				if err := saveGame(g.uiSaveSlotSelected, g.state, g.record, botKinds(g)); err != nil {
+					log.Printf("could not save to slot %v: %v", g.uiSaveSlotSelected+1, err)
+					g.uiSaveSlotMessage = "Could not save the game"
+				} else {
+					log.Printf("saved game to slot %v", g.uiSaveSlotSelected+1)
+					g.gameState = 1
 				}
 			} else {
-				// target tile is not owned by the current player, and there is a piece on the selected tile
-
-				playerPiece := g.board.Tiles[selectedX][selectedY].Piece
-				targetPiece := g.board.Tiles[targetX][targetY].Piece
-
-				switch playerPiece.Value {
-				case 1, 3, 5:
-					// piece is a gatherer, and can not attack other pieces
-					g.InvalidTile = Position{targetX, targetY}
-				case 2, 4:
-					// pieces is a soldier, and can attack other pieces
-
-					if targetPiece.Value == playerPiece.Value {
-						// remove both pieces from the board and players pieces array
-
-						targetPlayerId := g.board.Tiles[targetX][targetY].Piece.PlayerIndex
-						for i, piece := range g.players[targetPlayerId].Pieces {
-							if piece.Position.X == targetX && piece.Position.Y == targetY {
-								g.players[targetPlayerId].Pieces = append(g.players[targetPlayerId].Pieces[:i], g.players[targetPlayerId].Pieces[i+1:]...)
-								break // ensures that when the piece is removed, the last itteration will not cause an out of range exception
-							}
-						}
-
-						// find and remove the selected piece from the players pieces array
-						selectedPlayerId := g.board.Tiles[selectedX][selectedY].Piece.PlayerIndex
-						for i, piece := range g.players[selectedPlayerId].Pieces {
-							if piece.Position.X == selectedX && piece.Position.Y == selectedY {
-								g.players[selectedPlayerId].Pieces = append(g.players[selectedPlayerId].Pieces[:i], g.players[selectedPlayerId].Pieces[i+1:]...)
-								break // ensures that when the piece is removed, the last itteration will not cause an out of range exception
-							}
-						}
-
-						// update the user with the new highlighted and selected tiles
-						g.HighlightedTile = Position{targetX, targetY}
-						g.SelectedTile = Position{-1, -1} //selected piece has been removed from the board
-
-						usePlayerAction(g)
-
-					} else if targetPiece.Value < playerPiece.Value {
-						// remove the targets piece from the board and players pieces array
-						// move the players piece to the target tile with reduced value by targets value
-
-						// ensures that when the piece is removed, the last itteration will not cause an out of range exception
-						targetPlayerId := g.board.Tiles[targetX][targetY].Piece.PlayerIndex
-						removePieceFromPlayer(g, targetPlayerId, targetX, targetY)
-
-						// get the selected piece index in the player pieces list
-						selectedPlayerId := g.board.Tiles[selectedX][selectedY].Piece.PlayerIndex
-						pieceIndex := findPlayerPieceIndex(g, g.players[selectedPlayerId].Pieces, selectedX, selectedY)
-
-						// move the player pieces to the target tile
-						movePieceToTile(g, selectedPlayerId, pieceIndex, targetX, targetY)
-						// set the new value of the piece
-						remaintingValue := g.players[selectedPlayerId].Pieces[pieceIndex].Value - targetPiece.Value
-						setPieceValue(g, selectedPlayerId, pieceIndex, remaintingValue)
-
-						g.HighlightedTile = Position{targetX, targetY}
-
-						g.SelectedTile = g.HighlightedTile
-						usePlayerAction(g)
-					} else {
-						// targetPices values is larger, and will absorb the selected pieces value
-
-						// Reduce the value of the target piece by the players piece value
-						targetPlayerId := g.board.Tiles[targetX][targetY].Piece.PlayerIndex
-						for i, piece := range g.players[targetPlayerId].Pieces {
-							if piece.Position.X == targetX && piece.Position.Y == targetY {
-								g.players[targetPlayerId].Pieces[i].Value -= g.board.Tiles[selectedX][selectedY].Piece.Value
-								break // ensures that when the piece is removed, the last itteration will not cause an out of range exception
-							}
-						}
-
-						// find and remove the selected piece from the players pieces array
-						selectedPlayerId := g.board.Tiles[selectedX][selectedY].Piece.PlayerIndex
-						for i, piece := range g.players[selectedPlayerId].Pieces {
-							if piece.Position.X == selectedX && piece.Position.Y == selectedY {
-								g.players[selectedPlayerId].Pieces = append(g.players[selectedPlayerId].Pieces[:i], g.players[selectedPlayerId].Pieces[i+1:]...)
-								break // ensures that when the piece is removed, the last itteration will not cause an out of range exception
-							}
-						}
-
-						g.HighlightedTile = Position{targetX, targetY}
-
-						// update the user with the new highlighted and selected tiles
-						g.SelectedTile = Position{-1, -1}
-
-						usePlayerAction(g)
-					}
-				case 6:
-					// piece is a outpost, and can only attack with a value of 1
-
-					if targetPiece.Value == 1 {
-						// remove the target piece from the player pieces array
-						//subtract one as the player id is 1 based, and the players array is zero based
-
-						// find the piece from the target player pieces array, and remove the target piece from the array
-						targetPlayerId := g.board.Tiles[targetX][targetY].Piece.PlayerIndex
-						for i, piece := range g.players[targetPlayerId].Pieces {
-							if piece.Position.X == targetX && piece.Position.Y == targetY {
-								g.players[targetPlayerId].Pieces = append(g.players[targetPlayerId].Pieces[:i], g.players[targetPlayerId].Pieces[i+1:]...)
-								break // ensures that when the piece is removed, the last itteration will not cause an out of range exception
-							}
-						}
-
-						usePlayerAction(g)
+				loaded, record, bots, err := loadGame(g.uiSaveSlotSelected)
+				if err != nil {
+					log.Printf("could not load slot %v: %v", g.uiSaveSlotSelected+1, err)
+					g.uiSaveSlotMessage = "Could not load this slot"
+				} else {
+					log.Printf("loaded game from slot %v", g.uiSaveSlotSelected+1)
+					disconnectOnline(g)
+					g.state = loaded
+					setBots(g, bots)
+					clearHistory(g)
+					if record.Seats != nil {
+						resumeRecording(g, record)
 					} else {
-						// target piece will reduce in value by 1 by being attacked by the outpost
-
-						// Find the index of the attacked piece in the player's pieces array
-						targetPlayerId := g.board.Tiles[targetX][targetY].Piece.PlayerIndex
-						for i, piece := range g.players[targetPlayerId].Pieces {
-							if piece.Position.X == targetX && piece.Position.Y == targetY {
-								g.players[targetPlayerId].Pieces[i].Value = g.players[targetPlayerId].Pieces[i].Value - 1
-								break
-							}
-						}
-
-						usePlayerAction(g)
+						stopRecording(g)
 					}
+					g.InvalidTile = rules.NoPosition
+					fitTileSize(g)
+					focusCurrentPlayer(g)
+					g.gameState = 0
 				}
 			}
-		}
-	}
-}
-
-func removePieceFromPlayer(g *Game, playerId int, positionX int, positionY int) {
-	for i, piece := range g.players[playerId].Pieces {
-		if piece.Position.X == positionX && piece.Position.Y == positionY {
-			g.players[playerId].Pieces = append(g.players[playerId].Pieces[:i], g.players[playerId].Pieces[i+1:]...)
-			break
-		}
-	}
-}
-
-func setPieceValue(g *Game, payerId int, pieceIndex int, newValue int) {
-	g.players[payerId].Pieces[pieceIndex].Value = newValue
-}
-
-func movePieceToTile(g *Game, playerId int, pieceIndex int, positionX int, positionY int) {
-	g.players[playerId].Pieces[pieceIndex].Position = Position{positionX, positionY}
-}
-
-// findPlayerPieceIndex, loop thought the player, and find the index of the desired piece
-func findPlayerPieceIndex(g *Game, playerPieces []Piece, positionX int, positionY int) int {
-	for i, piece := range playerPieces {
-		if piece.Position.X == positionX && piece.Position.Y == positionY {
-			return i
-		}
-	}
-	return -1
-}
-
-func usePlayerAction(g *Game) {
-
-	// check if the player has only 1 action left, if so, the last action was just used
-	if g.players[g.turn].Actions <= 1 {
-		g.players[g.turn].Actions = 0
-
-		log.Printf("End Turn, %v has 0 actions remaining", g.players[g.turn].Name)
-		if g.turn == (len(g.players) - 1) {
-			g.turn = 0
-		} else {
-			g.turn++
-		}
-		log.Printf("It is now %v's turn", g.players[g.turn].Name)
-
-		// Reset the actions for the new player's turn
-		updatePlayerActions(g)
-
-		if g.players[g.turn].Actions <= 0 {
-			// the current player has no actiosn left due to no pieces or no way to generate actions
-
-			// check if active players is only one
-			if len(g.players) == 1 {
-				// only one player left, so end the game
-				log.Printf("Game over! Player %s has won the game", g.players[0].Name)
-				g.GameOver = true
-
-				//allow the playeer to play continuously
-				g.turn = 0
-				updatePlayerActions(g)
+		} else if g.gameState == 7 {
+			// replay picker
+			if len(g.uiReplayNames) > 0 {
+				if err := openReplay(g, g.uiReplayNames[g.uiReplaySelected]); err != nil {
+					log.Printf("could not open replay %v: %v", g.uiReplayNames[g.uiReplaySelected], err)
+					g.uiReplayMessage = "Could not open this replay"
+				}
+			}
+		} else if g.gameState == 8 {
+			// play or pause the replay, starting again if it is at the end
+			g.replay.autoplay = !g.replay.autoplay
+			if g.replay.autoplay && g.replay.index == len(g.replay.states)-1 {
+				seekReplay(g, 0)
+			}
+		} else if g.gameState == 6 {
+			// results, set up the next game
+			g.uiNewGameSectionHighlighted = 0
+			g.uiStartNewGameButton = false
+			g.gameState = 3
+		} else if g.gameState == 5 {
+			// settings
+			if g.uiSettingsSelected == settingsRowBack {
+				closeSettings(g)
+			} else if g.uiSettingsSelected == settingsRowKeyBindings {
+				openKeyBindings(g)
 			} else {
-				// next player started with 0 actions and will be skipped. call own funciton to try following player
-				usePlayerAction(g)
+				changeSetting(g, g.uiSettingsSelected, 1)
 			}
-		}
-	} else {
-		// Decrease the current player's actions by 1
-		g.players[g.turn].Actions--
-	}
-}
+		} else if g.gameState == 2 {
+			// new game confirmation
+			if g.uiNewGameConfirmation {
+				// yes start new game
+				g.gameState = 3
+				g.uiMenueSelectedButton = 0
+				g.uiNewGameConfirmation = false
+
+				//reset game variables
+				g.SelectedTile = rules.NoPosition
+				g.HighlightedTile = rules.NoPosition
 
-func updatePlayerActions(g *Game) {
-
-	// clear up from the previous players turn
-	g.SelectedTile = Position{X: -1, Y: -1}
-
-	log.Printf("Setting player action for new turn: ")
-
-	// loop through the players pieces and set their actions based on value
-	for i, piece := range g.players[g.turn].Pieces {
-
-		if i == 0 {
-			// set the hightlighted tile to be the oldest pieces of the player
-			g.HighlightedTile = piece.Position
-		}
-
-		switch piece.Value {
-		case 1:
-			g.players[g.turn].Actions += 1
-			log.Printf(", %v +1", piece.Value)
-		case 3:
-			g.players[g.turn].Actions += 2
-			log.Printf(", %v +2", piece.Value)
-
-		case 5, 6:
-			g.players[g.turn].Actions += 3
-			log.Printf(", %v +3", piece.Value)
-		case 2, 4:
-			log.Printf(", %v -", piece.Value)
-		default:
-			log.Printf(", %v ?", piece.Value)
-		}
-	}
-
-	// printf the current players name and number of actions left and number of pieces they have
-	log.Printf("Player %s starts their turn with %d actions and %d pieces", g.players[g.turn].Name, g.players[g.turn].Actions, len(g.players[g.turn].Pieces))
-}
+			} else {
+				// reset the menue to
+				g.gameState = 1
+				g.uiMenueSelectedButton = 0
+			}
+		} else if g.gameState == 9 {
+			// online menue
+			pressOnlineRow(g)
+		} else if g.gameState == 10 {
+			// key bindings
+			pressBindingsRow(g)
+		} else if g.gameState == 3 {
+			// new game screen
+			if inLobby(g) {
+				// the room on the server decides who plays where
+				pressLobby(g)
+			} else if onBoardSizeRow(g) {
+				changeBoardSize(g, g.uiNewGameSectionHighlighted, 1)
+			} else if g.uiStartNewGameButton {
+				// Try to start new game button pressed
+
+				numberOfPlayers := 0
+				for _, section := range g.uiNewGameSectionPlayer {
+					if section != -1 {
+						numberOfPlayers++
+					}
+				}
+				// only start a new game if at least one player has been selected
+				if numberOfPlayers > 0 {
+					// start new game
+					disconnectOnline(g)
+					g.state = rules.NewGame(g.settings.BoardWidth, g.settings.BoardHeight, g.uiNewGameSectionPlayer)
+					createBots(g, g.uiNewGameSectionPlayer, g.uiNewGameSectionBot)
+					clearHistory(g)
+					startRecording(g, g.settings.BoardWidth, g.settings.BoardHeight, g.uiNewGameSectionPlayer)
+					fitTileSize(g)
+					focusCurrentPlayer(g)
+					g.gameState = 0
+				}
+			} else {
 
-// Update proceeds the game state. Update is called every frame (1/60[s] by default).
-func (g *Game) Update() error {
-	// List of keys to check
-	keys := []ebiten.Key{
-		ebiten.KeyEscape,
-		ebiten.KeyEnter,
-		ebiten.KeySpace,
-		ebiten.KeyArrowUp,
-		ebiten.KeyArrowDown,
-		ebiten.KeyArrowLeft,
-		ebiten.KeyArrowRight,
-	}
+				// check if player assigned to section, toggle next player in, if empty
+				if g.uiNewGameSectionPlayer[g.uiNewGameSectionHighlighted] == -1 {
+					//find out how many players are currently on the board
+					numberOfPlayers := 1
+					for _, section := range g.uiNewGameSectionPlayer {
+						if section != -1 {
+							numberOfPlayers++
+						}
+					}
+					g.uiNewGameSectionPlayer[g.uiNewGameSectionHighlighted] = numberOfPlayers
+				} else {
+					g.uiNewGameSectionPlayer[g.uiNewGameSectionHighlighted] = -1
+					g.uiNewGameSectionBot[g.uiNewGameSectionHighlighted] = ""
 
-	for _, key := range keys {
-		if ebiten.IsKeyPressed(key) {
-			// If the key is pressed and it was not pressed in the previous frame, log it
-			if !g.keyStates[key] {
-				g.keyStates[key] = true
-
-				// function to handle key presses
-				switch key {
-				case ebiten.KeyEscape:
-					log.Println("esc")
-
-					// menue is game state 1, and can only be shown when game is running in state 0
-					if g.gameState == 0 {
-						// menue state
-						g.gameState = 1
-					} else if g.gameState == 1 {
-						// play state
-						g.gameState = 0
+					// make sure that there is no missing id's in the player position, but maintain the relative player order
+					type Section struct {
+						origonalIndex int
+						playerId      int
 					}
 
-				case ebiten.KeyEnter:
-					log.Println("enter")
-					//next players turn and reset if all players have moved
+					// create the empty data for the helper struct, and defualt all places to empty
+					playerPositions := make([]Section, 4)
+					for i := range playerPositions {
+						playerPositions[i].playerId = -1
+					}
 
-					if g.gameState == 0 {
-						if g.turn == (len(g.players) - 1) {
-							g.turn = 0
-						} else {
-							g.turn++
+					for i, section := range g.uiNewGameSectionPlayer {
+						if section != -1 { // Only consider valid player IDs
+							playerPositions[section-1] = Section{i, section}
 						}
-
-						updatePlayerActions(g)
 					}
 
-				case ebiten.KeySpace:
-					log.Println("space")
-					// The SelectedTile already highlighted, deselect it, else set
-					if g.gameState == 0 {
-						if g.SelectedTile.X == -1 && g.SelectedTile.Y == -1 {
-							// is deselected, so automatically set
-
-							// get the pice one the selected tile, and see if belongs to current player
-							if g.board.Tiles[g.HighlightedTile.X][g.HighlightedTile.Y].Piece.PlayerIndex == g.players[g.turn].PlayerIndex {
-								// todo, only select if the active players peice. else flash red
-								log.Println("\tSelected tile")
-								g.SelectedTile = g.HighlightedTile
-							} else {
-								// log piece at tile
-								log.Printf("\tNot belonging to current player")
-							}
-						} else {
-							// is selected, check if selecting same tile, to deselect it
-							if g.SelectedTile == g.HighlightedTile {
-								g.SelectedTile = Position{X: -1, Y: -1}
-							} else {
-								// is selected, different tile so select it.
-
-								// NB behavior to be revised as this should find a path to the newly selected tile
-								// then see if is a valid move to move the selected piece to that location etc
-								g.SelectedTile = Position{X: -1, Y: -1}
-							}
-						}
-					} else if g.gameState == 1 {
-						// pause menue
-						switch g.uiMenueSelectedButton {
-						case 0:
-							log.Println("Resume")
-							// resume play state
-							g.gameState = 0
-						case 1:
-							log.Println("New Game")
-							// confirmation to make new game
-							g.gameState = 2
-						case 2:
-							log.Println("Load")
-						case 3:
-							log.Println("Settings")
-						case 4:
-							log.Println("Save")
-						case 5:
-							log.Println("Exit")
+					numberOfPlayers := 0
+					for _, section := range g.uiNewGameSectionPlayer {
+						if section != -1 {
+							numberOfPlayers++
 						}
-					} else if g.gameState == 2 {
-						// new game confirmation
-						if g.uiNewGameConfirmation {
-							// yes start new game
-							g.gameState = 3
-							g.uiMenueSelectedButton = 0
-							g.uiNewGameConfirmation = false
-
-							//reset game variables
-							g.GameOver = false
-							g.SelectedTile = Position{-1, -1}
-							g.HighlightedTile = Position{-1, -1}
-							g.turn = 0
+					}
 
-						} else {
-							// reset the menue to
-							g.gameState = 1
-							g.uiMenueSelectedButton = 0
-						}
-					} else if g.gameState == 3 {
-						// new game screen
-						if g.uiStartNewGameButton {
-							// Try to start new game button pressed
-
-							numberOfPlayers := 0
-							for _, section := range g.uiNewGameSectionPlayer {
-								if section != -1 {
-									numberOfPlayers++
-								}
-							}
-							// only start a new game if at least one player has been selected
-							if numberOfPlayers > 0 {
-								// start new game
-								g.players = createPlayers(g.uiNewGameSectionPlayer)
-								setPiecesOnBoardFromPlayers(g)
-								updatePlayerActions(g)
-								g.gameState = 0
+					for i := 1; i <= numberOfPlayers; {
+						hasIdex := false
+						for p := range g.uiNewGameSectionPlayer {
+							if playerPositions[p].playerId == i {
+								hasIdex = true
+								break
 							}
+						}
+						// check if i has been found, else reduce the value of the others to close the missing index
+						if hasIdex {
+							// player i has been found in SectionPlayer, check next index
+							i++
 						} else {
-
-							// check if player assigned to section, toggle next player in, if empty
-							if g.uiNewGameSectionPlayer[g.uiNewGameSectionHighlighted] == -1 {
-								//find out how many players are currently on the board
-								numberOfPlayers := 1
-								for _, section := range g.uiNewGameSectionPlayer {
-									if section != -1 {
-										numberOfPlayers++
-									}
-								}
-								g.uiNewGameSectionPlayer[g.uiNewGameSectionHighlighted] = numberOfPlayers
-							} else {
-								g.uiNewGameSectionPlayer[g.uiNewGameSectionHighlighted] = -1
-
-								// make sure that there is no missing id's in the player position, but maintain the relative player order
-								type Section struct {
-									origonalIndex int
-									playerId      int
-								}
-
-								// create the empty data for the helper struct, and defualt all places to empty
-								playerPositions := make([]Section, 4)
-								for i := range playerPositions {
-									playerPositions[i].playerId = -1
-								}
-
-								for i, section := range g.uiNewGameSectionPlayer {
-									if section != -1 { // Only consider valid player IDs
-										playerPositions[section-1] = Section{i, section}
-									}
-								}
-
-								numberOfPlayers := 0
-								for _, section := range g.uiNewGameSectionPlayer {
-									if section != -1 {
-										numberOfPlayers++
-									}
-								}
-
-								for i := 1; i <= numberOfPlayers; {
-									hasIdex := false
-									for p := range g.uiNewGameSectionPlayer {
-										if playerPositions[p].playerId == i {
-											hasIdex = true
-											break
-										}
-									}
-									// check if i has been found, else reduce the value of the others to close the missing index
-									if hasIdex {
-										// player i has been found in SectionPlayer, check next index
-										i++
-									} else {
-										//reduce all the player ids, after the corected ones of the section by 1
-										for p := (i - 1); p < len(playerPositions); p++ {
-											if playerPositions[p].playerId > 1 {
-												playerPositions[p].playerId = playerPositions[p].playerId - 1
-											}
-										}
-									}
-								}
-								//Assign the section array back to the g object
-								for i := range playerPositions {
-									// only set the ones that have a player value
-									if playerPositions[i].playerId != -1 {
-										g.uiNewGameSectionPlayer[playerPositions[i].origonalIndex] = playerPositions[i].playerId
-									}
+							//reduce all the player ids, after the corected ones of the section by 1
+							for p := (i - 1); p < len(playerPositions); p++ {
+								if playerPositions[p].playerId > 1 {
+									playerPositions[p].playerId = playerPositions[p].playerId - 1
 								}
 							}
 						}
 					}
-				case ebiten.KeyArrowLeft:
-					log.Println("left")
-					if g.gameState == 0 {
-						// if the highlighter is not at the left of the board, move it left
-						if g.HighlightedTile.X > 0 {
-							handleTileMove(g, -1, 0)
-						}
-					} else if g.gameState == 2 {
-						// new game confirmation
-						g.uiNewGameConfirmation = !g.uiNewGameConfirmation
-					} else if g.gameState == 3 {
-						// move the highlighted based on the arrow keys
-						if g.uiNewGameSectionHighlighted == 1 || g.uiNewGameSectionHighlighted == 3 {
-							g.uiNewGameSectionHighlighted = g.uiNewGameSectionHighlighted - 1
-						}
-					}
-				case ebiten.KeyArrowRight:
-					log.Println("right")
-					if g.gameState == 0 {
-						// if the highlighter is not at the right of the board, move it right
-						if g.HighlightedTile.X < g.board.Width {
-							handleTileMove(g, 1, 0)
-						}
-					} else if g.gameState == 2 {
-						// new game confirmation
-						g.uiNewGameConfirmation = !g.uiNewGameConfirmation
-					} else if g.gameState == 3 {
-						if g.uiNewGameSectionHighlighted == 0 || g.uiNewGameSectionHighlighted == 2 {
-							g.uiNewGameSectionHighlighted = g.uiNewGameSectionHighlighted + 1
-						}
-					}
-				case ebiten.KeyArrowUp:
-					log.Println("up")
-					if g.gameState == 0 {
-						// if the highlighter is not at the top of the board, move it up
-						if g.HighlightedTile.Y > 0 {
-							handleTileMove(g, 0, -1)
-						}
-					} else if g.gameState == 1 {
-						// pause menue
-						if g.uiMenueSelectedButton < 0 {
-							g.uiMenueSelectedButton = 0
-						} else if g.uiMenueSelectedButton > g.uiMenueButtonNumber {
-							g.uiMenueSelectedButton = g.uiMenueButtonNumber
-						} else {
-							g.uiMenueSelectedButton--
-						}
-					} else if g.gameState == 3 {
-						if g.uiNewGameSectionHighlighted == 2 || g.uiNewGameSectionHighlighted == 3 {
-							g.uiNewGameSectionHighlighted = g.uiNewGameSectionHighlighted - 2
-						} else if g.uiStartNewGameButton {
-							// new game button is currently selected, and now go back to selctions
-							g.uiStartNewGameButton = false
-							g.uiNewGameSectionHighlighted = 2
-						}
-					}
-				case ebiten.KeyArrowDown:
-					log.Println("down")
-					if g.gameState == 0 {
-						// if the highlighter is not at the bottom of the board, move it down
-						if g.HighlightedTile.Y < g.board.Height {
-							handleTileMove(g, 0, 1)
-						}
-					} else if g.gameState == 1 {
-						// pause menue
-						if g.uiMenueSelectedButton < 0 {
-							g.uiMenueSelectedButton = 0
-						} else if g.uiMenueSelectedButton > g.uiMenueButtonNumber {
-							g.uiMenueSelectedButton = g.uiMenueButtonNumber
-						} else {
-							g.uiMenueSelectedButton++
-						}
-					} else if g.gameState == 3 {
-						if g.uiNewGameSectionHighlighted == 0 || g.uiNewGameSectionHighlighted == 1 {
-							g.uiNewGameSectionHighlighted = g.uiNewGameSectionHighlighted + 2
-						} else if g.uiNewGameSectionHighlighted == 2 || g.uiNewGameSectionHighlighted == 3 {
-							// wanting to go to the new game button
-							g.uiNewGameSectionHighlighted = -1
-							g.uiStartNewGameButton = true
+					//Assign the section array back to the g object
+					for i := range playerPositions {
+						// only set the ones that have a player value
+						if playerPositions[i].playerId != -1 {
+							g.uiNewGameSectionPlayer[playerPositions[i].origonalIndex] = playerPositions[i].playerId
 						}
 					}
 				}
 			}
-
-			if g.gameState == 0 {
-				// when a key is pressed, make sure that the board has been updated with the latest state of the player positions
-				// clear the board of peices and re-add them to the board
-				clearPiecesFromBoard(g)
-				// update the board with the piece position
-				setPiecesOnBoardFromPlayers(g)
+		}
+	case actionTarget:
+		log.Println("target")
+		if g.gameState == 0 {
+			startTargeting(g)
+		}
+	case actionUndo:
+		log.Println("undo")
+		if g.gameState == 0 {
+			undo(g)
+		}
+	case actionRedo:
+		log.Println("redo")
+		if g.gameState == 0 {
+			redo(g)
+		}
+	case actionReplayStart:
+		if g.gameState == 8 {
+			seekReplay(g, 0)
+		}
+	case actionReplayEnd:
+		if g.gameState == 8 {
+			seekReplay(g, len(g.replay.states)-1)
+		}
+	case actionCursorLeft:
+		log.Println("left")
+		if g.gameState == 0 {
+			// if the highlighter is not at the left of the board, move it left
+			if g.HighlightedTile.X > 0 {
+				handleTileMove(g, -1, 0)
 			}
-
-		} else {
-			// If the key is not pressed, reset its state
-			g.keyStates[key] = false
+		} else if g.gameState == 2 {
+			// new game confirmation
+			g.uiNewGameConfirmation = !g.uiNewGameConfirmation
+		} else if g.gameState == 3 {
+			// move the highlighted based on the arrow keys
+			if g.uiNewGameSectionHighlighted == 1 || g.uiNewGameSectionHighlighted == 3 || g.uiNewGameSectionHighlighted == newGameRowsCell {
+				g.uiNewGameSectionHighlighted = g.uiNewGameSectionHighlighted - 1
+			}
+		} else if g.gameState == 5 {
+			changeSetting(g, g.uiSettingsSelected, -1)
+		} else if g.gameState == 8 {
+			stepReplay(g, -1)
 		}
-	}
-
-	return nil
-}
-
-func clearPiecesFromBoard(g *Game) {
-	for x, row := range g.board.Tiles {
-		for y := range row {
-			g.board.Tiles[x][y].Piece = Piece{}
+	case actionCursorRight:
+		log.Println("right")
+		if g.gameState == 0 {
+			// if the highlighter is not at the right of the board, move it right
+			if g.HighlightedTile.X < g.state.Board.Width {
+				handleTileMove(g, 1, 0)
+			}
+		} else if g.gameState == 2 {
+			// new game confirmation
+			g.uiNewGameConfirmation = !g.uiNewGameConfirmation
+		} else if g.gameState == 3 {
+			if g.uiNewGameSectionHighlighted == 0 || g.uiNewGameSectionHighlighted == 2 || g.uiNewGameSectionHighlighted == newGameColumnsCell {
+				g.uiNewGameSectionHighlighted = g.uiNewGameSectionHighlighted + 1
+			}
+		} else if g.gameState == 5 {
+			changeSetting(g, g.uiSettingsSelected, 1)
+		} else if g.gameState == 8 {
+			stepReplay(g, 1)
 		}
-	}
-}
-
-func setPiecesOnBoardFromPlayers(g *Game) {
-	for _, player := range g.players {
-		for _, piece := range player.Pieces {
-
-			g.board.Tiles[piece.Position.X][piece.Position.Y].Piece = piece
+	case actionCursorUp:
+		log.Println("up")
+		if g.gameState == 0 {
+			// if the highlighter is not at the top of the board, move it up
+			if g.HighlightedTile.Y > 0 {
+				handleTileMove(g, 0, -1)
+			}
+		} else if g.gameState == 1 {
+			// pause menue
+			if g.uiMenueSelectedButton < 0 {
+				g.uiMenueSelectedButton = 0
+			} else if g.uiMenueSelectedButton > g.uiMenueButtonNumber {
+				g.uiMenueSelectedButton = g.uiMenueButtonNumber
+			} else {
+				g.uiMenueSelectedButton--
+			}
+		} else if g.gameState == 4 {
+			// save slot picker
+			if g.uiSaveSlotSelected > 0 {
+				g.uiSaveSlotSelected--
+			}
+		} else if g.gameState == 5 {
+			// settings
+			if g.uiSettingsSelected > 0 {
+				g.uiSettingsSelected--
+			}
+		} else if g.gameState == 7 {
+			// replay picker
+			if g.uiReplaySelected > 0 {
+				g.uiReplaySelected--
+			}
+		} else if g.gameState == 8 {
+			stepReplayTurn(g, -1)
+		} else if g.gameState == 9 {
+			// online menue
+			if g.uiOnlineSelected > 0 {
+				g.uiOnlineSelected--
+			}
+		} else if g.gameState == 10 {
+			// key bindings
+			if g.uiBindingsSelected > 0 {
+				g.uiBindingsSelected--
+			}
+		} else if g.gameState == 3 {
+			if g.uiNewGameSectionHighlighted >= 2 {
+				g.uiNewGameSectionHighlighted = g.uiNewGameSectionHighlighted - 2
+			} else if g.uiStartNewGameButton {
+				// new game button is currently selected, and now go back to the board size
+				g.uiStartNewGameButton = false
+				g.uiNewGameSectionHighlighted = newGameColumnsCell
+			}
+		}
+	case actionCursorDown:
+		log.Println("down")
+		if g.gameState == 0 {
+			// if the highlighter is not at the bottom of the board, move it down
+			if g.HighlightedTile.Y < g.state.Board.Height {
+				handleTileMove(g, 0, 1)
+			}
+		} else if g.gameState == 1 {
+			// pause menue
+			if g.uiMenueSelectedButton < 0 {
+				g.uiMenueSelectedButton = 0
+			} else if g.uiMenueSelectedButton > g.uiMenueButtonNumber {
+				g.uiMenueSelectedButton = g.uiMenueButtonNumber
+			} else {
+				g.uiMenueSelectedButton++
+			}
+		} else if g.gameState == 4 {
+			// save slot picker
+			if g.uiSaveSlotSelected < saveSlots-1 {
+				g.uiSaveSlotSelected++
+			}
+		} else if g.gameState == 5 {
+			// settings
+			if g.uiSettingsSelected < settingsRowCount-1 {
+				g.uiSettingsSelected++
+			}
+		} else if g.gameState == 7 {
+			// replay picker
+			if g.uiReplaySelected < len(g.uiReplayNames)-1 {
+				g.uiReplaySelected++
+			}
+		} else if g.gameState == 8 {
+			stepReplayTurn(g, 1)
+		} else if g.gameState == 9 {
+			// online menue
+			if g.uiOnlineSelected < onlineRowCount-1 {
+				g.uiOnlineSelected++
+			}
+		} else if g.gameState == 10 {
+			// key bindings
+			if g.uiBindingsSelected < bindingsRowCount-1 {
+				g.uiBindingsSelected++
+			}
+		} else if g.gameState == 3 {
+			if g.uiNewGameSectionHighlighted >= 0 && g.uiNewGameSectionHighlighted <= 3 {
+				// through the sections and on to the board size
+				g.uiNewGameSectionHighlighted = g.uiNewGameSectionHighlighted + 2
+			} else if onBoardSizeRow(g) {
+				// wanting to go to the new game button
+				g.uiNewGameSectionHighlighted = -1
+				g.uiStartNewGameButton = true
+			}
 		}
 	}
 }
@@ -844,166 +674,157 @@ func (g *Game) Draw(screen *ebiten.Image) {
 	if g.gameState == 0 {
 		// playing game state
 
-		//loop through the tiles of the board
-		for x := 0; x <= g.board.Width; x++ {
-			for y := 0; y <= g.board.Height; y++ {
-				xPos := x * g.board.TileSize
-				yPos := y * g.board.TileSize
-
-				// Draw the board checkerboard black and white squares filled
-				if (x+y)%2 == 0 {
-					// Using board.TileSize as the size of each square draw a black square
-					vector.DrawFilledRect(screen, float32(xPos), float32(yPos),
-						float32(g.board.TileSize), float32(g.board.TileSize), color.White, true)
-				} else {
-					vector.DrawFilledRect(screen, float32(xPos), float32(yPos),
-						float32(g.board.TileSize), float32(g.board.TileSize), color.Black, true)
-				}
-			}
-		}
+		drawBoardTiles(screen, g.state.Board, g.tileSize)
 
 		// drawImage of yellow box on highlighter position// there is always a highlighted tile
-		highlightedBox := ebiten.NewImage(g.board.TileSize, g.board.TileSize)
+		highlightedBox := ebiten.NewImage(g.tileSize, g.tileSize)
 		highlightedBox.Fill(color.RGBA{0xff, 0xff, 0x00, 0xff})
 		op := &ebiten.DrawImageOptions{}
-		op.GeoM.Translate(float64(g.HighlightedTile.X*g.board.TileSize), float64(g.HighlightedTile.Y*g.board.TileSize))
+		op.GeoM.Translate(float64(g.HighlightedTile.X*g.tileSize), float64(g.HighlightedTile.Y*g.tileSize))
 		screen.DrawImage(highlightedBox, op)
 
 		// Is there a Invalid Tile
 		if g.InvalidTile.X != -1 && g.InvalidTile.Y != -1 {
-			invalidBox := ebiten.NewImage(g.board.TileSize, g.board.TileSize)
+			invalidBox := ebiten.NewImage(g.tileSize, g.tileSize)
 			invalidBox.Fill(color.RGBA{0xff, 0x00, 0x00, 0xff})
 			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Translate(float64(g.InvalidTile.X*g.board.TileSize), float64(g.InvalidTile.Y*g.board.TileSize))
+			op.GeoM.Translate(float64(g.InvalidTile.X*g.tileSize), float64(g.InvalidTile.Y*g.tileSize))
 			screen.DrawImage(invalidBox, op)
-			g.InvalidTile = Position{-1, -1}
+			g.InvalidTile = rules.NoPosition
 		}
 
 		// Is there a selected Tile
 		if g.SelectedTile.X != -1 && g.SelectedTile.Y != -1 {
 			// drawImage of green box on selected position
 			var boaderSize = 5
-			selectedBox := ebiten.NewImage(g.board.TileSize-(boaderSize*2), g.board.TileSize-(boaderSize*2))
+			selectedBox := ebiten.NewImage(g.tileSize-(boaderSize*2), g.tileSize-(boaderSize*2))
 			selectedBox.Fill(color.RGBA{0x00, 0xff, 0x00, 0xff})
 			op := &ebiten.DrawImageOptions{}
-			op.GeoM.Translate(float64(g.SelectedTile.X*g.board.TileSize+boaderSize), float64(g.SelectedTile.Y*g.board.TileSize+boaderSize))
+			op.GeoM.Translate(float64(g.SelectedTile.X*g.tileSize+boaderSize), float64(g.SelectedTile.Y*g.tileSize+boaderSize))
 			screen.DrawImage(selectedBox, op)
 		}
 
-		for _, player := range g.players {
-			for _, piece := range player.Pieces {
-				// update the board with the piece position
-				xPos := piece.Position.X * g.board.TileSize
-				yPos := piece.Position.Y * g.board.TileSize
-
-				pieceBox := ebiten.NewImage(g.board.TileSize/2, g.board.TileSize/2)
-				pieceBox.Fill(g.board.Tiles[piece.Position.X][piece.Position.Y].Piece.Color)
-
-				// Draw the Piece box for player colour
-				op := &ebiten.DrawImageOptions{}
-				op.GeoM.Translate(float64(xPos+g.board.TileSize/4), float64(yPos+g.board.TileSize/4))
-				screen.DrawImage(pieceBox, op)
-
-				// Draw the Piece value
-				opT := &text.DrawOptions{}
-				opT.GeoM.Translate(float64(xPos+36), float64(yPos+28)) // note had to manually find the center based on 18 as the font size
-				opT.ColorScale.ScaleWithColor(color.White)
-				text.Draw(screen, fmt.Sprint(piece.Value), &text.GoTextFace{
-					Source: textSource,
-					Size:   18,
-				}, opT)
-			}
-		}
+		drawOutcomes(g, screen)
+		drawPath(g, screen)
+		drawPieces(screen, g.state, g.tileSize, textSource)
 
 		// Draw the Text for the Player Turns
 		uiPlayerStatusOp := &text.DrawOptions{}
-		uiPlayerStatusOp.GeoM.Translate(20, 660)
+		uiStatusY := (g.state.Board.Height + 1) * g.tileSize
+		uiPlayerStatusOp.GeoM.Translate(20, float64(uiStatusY+20))
 		uiPlayerStatusOp.ColorScale.ScaleWithColor(color.White)
-		text.Draw(screen, fmt.Sprintf("Player %v, has %v remaing",
-			g.players[g.turn].Name, g.players[g.turn].Actions), &text.GoTextFace{
+		uiPlayerStatus := fmt.Sprintf("Player %v, has %v remaing", g.state.CurrentPlayer().Name, g.state.CurrentPlayer().Actions)
+		if b := currentBot(g); b != nil {
+			uiPlayerStatus = fmt.Sprintf("Player %v (%v), is playing with %v remaing", g.state.CurrentPlayer().Name, controllerLabel(b.Name()), g.state.CurrentPlayer().Actions)
+			if botThinking(g) {
+				uiPlayerStatus = fmt.Sprintf("Player %v (%v), is thinking with %v remaing", g.state.CurrentPlayer().Name, controllerLabel(b.Name()), g.state.CurrentPlayer().Actions)
+			}
+		}
+		if g.network != nil && !isLocalTurn(g) && !g.state.GameOver {
+			uiPlayerStatus = fmt.Sprintf("Player %v, is playing online with %v remaing", g.state.CurrentPlayer().Name, g.state.CurrentPlayer().Actions)
+		}
+		if g.state.GameOver {
+			uiPlayerStatus = "Game over, start a new game from the 'esc' menue"
+		}
+		text.Draw(screen, uiPlayerStatus, &text.GoTextFace{
 			Source: textSource,
 			Size:   18,
 		}, uiPlayerStatusOp)
 
 		// Draw the text for basic instructions
 		uiControllsOp := &text.DrawOptions{}
-		uiControllsOp.GeoM.Translate(20, 680)
-		tutorialMsg := "Controlles: 'space' select piece 'arow keys' move pieces"
+		uiControllsOp.GeoM.Translate(20, float64(uiStatusY+40))
+		tutorialMsg := fmt.Sprintf("Controlles: '%v' select piece '%v/%v/%v/%v' move pieces", actionKeyName(g, actionSelect),
+			actionKeyName(g, actionCursorUp), actionKeyName(g, actionCursorLeft), actionKeyName(g, actionCursorDown), actionKeyName(g, actionCursorRight))
+		if g.uiPointerUsed {
+			tutorialMsg = "Tap a piece, then the tile it acts on"
+		}
+		if msg, ok := gamepadMessage(g); ok {
+			tutorialMsg = msg
+		}
+		if g.network != nil {
+			tutorialMsg = "Online: " + g.network.status
+		} else if g.broadcast != nil && g.broadcast.code != "" {
+			tutorialMsg = fmt.Sprintf("Broadcasting as game %v, watch at /static/watch.html", g.broadcast.code)
+		}
+		// while choosing what to do with a piece, the path or the legend for the tiles around it are shown instead
+		if msg, ok := pathMessage(g); ok {
+			tutorialMsg = msg
+		} else if drawOutcomeLegend(g, screen, textSource, 20, uiStatusY+40) {
+			tutorialMsg = ""
+		}
 		uiControllsOp.ColorScale.ScaleWithColor(color.White)
 		text.Draw(screen, fmt.Sprint(tutorialMsg), &text.GoTextFace{
 			Source: textSource,
 			Size:   18,
 		}, uiControllsOp)
 
+		// buttons for the mouse and touch, as there are no keys to end the turn or open the menue
+		if g.uiPointerUsed {
+			uiButtonColor := color.RGBA{0x33, 0x33, 0x33, 0xff}
+			if isLocalTurn(g) && !g.state.GameOver {
+				drawPlayButton(screen, playButton(g, false), uiButtonColor, textSource, "End Turn")
+			}
+			drawPlayButton(screen, playButton(g, true), uiButtonColor, textSource, "Menue")
+		}
+
 	} else if g.gameState == 1 {
 		// menue state
-		uiBorder := 50
-		uiButtonBorder := 20
-		uiSize := Position{g.screenSize.X - (uiBorder * 2), g.screenSize.Y - (uiBorder * 2)}
-		uiButtonHeight := 80 // (g.screenSize.Y - (2 * uiButtonBorder)) / (g.uiMenueButtonNumber + uiButtonBorder)
-		uiButtonWidth := uiSize.X - (uiButtonBorder * 2)
+		uiSize := rules.Position{X: g.screenSize.X - (pauseMenueBorder * 2), Y: g.screenSize.Y - (pauseMenueBorder * 2)}
 		uiBackgroundColor := color.RGBA{0x55, 0x55, 0x55, 0x55}
 		uiButtonColor := color.RGBA{0x33, 0x33, 0x33, 0xff}
 		uiButtonHighlightColor := color.RGBA{0x88, 0x88, 0x88, 0xff}
-		buttonLabels := []string{"Resume", "New Game", "Load", "Settings", "Save", "Exit"}
+		buttonLabels := []string{"Resume", "New Game", "Online", "Load", "Settings", "Save", "Replays", "Exit"}
+		if g.network != nil {
+			buttonLabels[2] = "Leave Online"
+		}
 
 		// Draw the ui menue background box
 		menueBox := ebiten.NewImage(uiSize.X, uiSize.Y)
 		menueBox.Fill(uiBackgroundColor)
 		menueDo := &ebiten.DrawImageOptions{}
-		menueDo.GeoM.Translate(float64(uiBorder), float64(uiBorder))
+		menueDo.GeoM.Translate(float64(pauseMenueBorder), float64(pauseMenueBorder))
 		screen.DrawImage(menueBox, menueDo)
 
-		buttonNextPosition := uiBorder - uiButtonHeight
 		for i := 0; i <= g.uiMenueButtonNumber; i++ {
-			buttonNextPosition += uiButtonBorder + uiButtonHeight
+			button := pauseMenueButton(g, i)
 			text := buttonLabels[i]
 
 			if g.uiMenueSelectedButton == i {
-				drawMenueButton(screen, uiBorder+uiButtonBorder, buttonNextPosition, uiButtonWidth, uiButtonHeight, uiButtonHighlightColor, textSource, text)
+				drawMenueButton(screen, button.Min.X, button.Min.Y, button.Dx(), button.Dy(), uiButtonHighlightColor, textSource, text)
 
 			} else {
-				drawMenueButton(screen, uiBorder+uiButtonBorder, buttonNextPosition, uiButtonWidth, uiButtonHeight, uiButtonColor, textSource, text)
+				drawMenueButton(screen, button.Min.X, button.Min.Y, button.Dx(), button.Dy(), uiButtonColor, textSource, text)
 			}
 
 		}
 	} else if g.gameState == 2 {
 		// new game confirmaiton
-		uiMenueWidth := 400
-		uiMenueHeight := 140
-		uiStartX := (g.screenSize.X / 2) - (uiMenueWidth / 2)
-		uiStarty := (g.screenSize.Y / 2) - (uiMenueHeight / 2)
-		uiButtonBorder := 25
+		uiBox := confirmBox(g)
 		uiBackgroundColor := color.RGBA{0x55, 0x55, 0x55, 0x55}
 		uiButtonColor := color.RGBA{0x33, 0x33, 0x33, 0xff}
 		uiButtonHighlightColor := color.RGBA{0x88, 0x88, 0x88, 0xff}
 
 		// Draw the ui menue background box
-		menueBox := ebiten.NewImage(uiMenueWidth, uiMenueHeight)
+		menueBox := ebiten.NewImage(uiBox.Dx(), uiBox.Dy())
 		menueBox.Fill(uiBackgroundColor)
 		menueDo := &ebiten.DrawImageOptions{}
-		menueDo.GeoM.Translate(float64(uiStartX), float64(uiStarty))
+		menueDo.GeoM.Translate(float64(uiBox.Min.X), float64(uiBox.Min.Y))
 		screen.DrawImage(menueBox, menueDo)
 
-		uiButtonWidth := (uiMenueWidth - (uiButtonBorder * 3)) / 2
-		uiButtonHeight := uiMenueHeight - (uiButtonBorder * 2)
-		uiButtonStartX := uiStartX + uiButtonBorder
-		uiButtonStartY := uiStarty + uiButtonBorder
+		no, yes := confirmButton(g, false), confirmButton(g, true)
 		if g.uiNewGameConfirmation {
-			drawMenueButton(screen, uiButtonStartX, uiButtonStartY, uiButtonWidth, uiButtonHeight, uiButtonColor, textSource, "No")
-			drawMenueButton(screen, uiButtonStartX+uiButtonWidth+uiButtonBorder, uiButtonStartY, uiButtonWidth, uiButtonHeight, uiButtonHighlightColor, textSource, "Yes")
+			drawMenueButton(screen, no.Min.X, no.Min.Y, no.Dx(), no.Dy(), uiButtonColor, textSource, "No")
+			drawMenueButton(screen, yes.Min.X, yes.Min.Y, yes.Dx(), yes.Dy(), uiButtonHighlightColor, textSource, "Yes")
 		} else {
-			drawMenueButton(screen, uiButtonStartX, uiButtonStartY, uiButtonWidth, uiButtonHeight, uiButtonHighlightColor, textSource, "No")
-			drawMenueButton(screen, uiButtonStartX+uiButtonWidth+uiButtonBorder, uiButtonStartY, uiButtonWidth, uiButtonHeight, uiButtonColor, textSource, "Yes")
+			drawMenueButton(screen, no.Min.X, no.Min.Y, no.Dx(), no.Dy(), uiButtonHighlightColor, textSource, "No")
+			drawMenueButton(screen, yes.Min.X, yes.Min.Y, yes.Dx(), yes.Dy(), uiButtonColor, textSource, "Yes")
 		}
 	} else if g.gameState == 3 {
 		// new game creation screen
-		uiBorder := 50
-		uiStartGameAreaHeight := 150
-		uiMessageBoxAreaHeight := 150
-		uiSectionWidth := (g.screenSize.X / 2) - (uiBorder / 2) - uiBorder
-		uiSectionHeight := ((g.screenSize.Y - uiStartGameAreaHeight - uiMessageBoxAreaHeight) / 2) - (uiBorder / 2) - uiBorder
-		uiMessageBoxStartY := (uiBorder * (2 + 1)) + (uiSectionHeight * 2)
+		uiBorder := newGameBorder
+		uiSectionWidth, uiSectionHeight := newGameSectionSize(g)
+		uiMessageBoxStartY := newGameMessageBoxY(g)
 		uiExcludedColor := color.RGBA{0x11, 0x11, 0x11, 0xff}
 		uiIncludedColor := color.RGBA{0x44, 0x44, 0x44, 0xff}
 		uiHighlightColor := color.RGBA{0x99, 0x99, 0x99, 0xff}
@@ -1011,12 +832,19 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		index := 0
 		for c := 0; c < 2; c++ {
 			for r := 0; r < 2; r++ {
-				startX := (uiBorder * (r + 1)) + (uiSectionWidth * r)
-				startY := (uiBorder * (c + 1)) + (uiSectionHeight * c)
+				startX, startY := newGameSection(g, index).Min.X, newGameSection(g, index).Min.Y
+
+				// a room on the server shows who has sat in each section instead
+				included := g.uiNewGameSectionPlayer[index] != -1
+				title := fmt.Sprintf("player%v", g.uiNewGameSectionPlayer[index])
+				subtitle := controllerLabel(g.uiNewGameSectionBot[index])
+				if inLobby(g) {
+					included, title, subtitle = lobbySection(g, index)
+				}
 
 				section := ebiten.NewImage(uiSectionWidth, uiSectionHeight)
 				// set the coresponding color, depending on if the section is included, excluded, or selected
-				if g.uiNewGameSectionPlayer[index] == -1 {
+				if !included {
 					section.Fill(uiExcludedColor)
 				} else {
 					section.Fill(uiIncludedColor)
@@ -1031,36 +859,70 @@ func (g *Game) Draw(screen *ebiten.Image) {
 				screen.DrawImage(section, sectionDo)
 
 				// draw text on section needs to be after drawing of the section
-				if g.uiNewGameSectionPlayer[index] != -1 {
+				if included {
 					op := &text.DrawOptions{}
 					op.GeoM.Translate(float64(startX+(uiSectionWidth/3)), float64(startY+18))
 					op.ColorScale.ScaleWithColor(color.White)
-					text.Draw(screen, fmt.Sprintf("player%v", g.uiNewGameSectionPlayer[index]), &text.GoTextFace{
+					text.Draw(screen, title, &text.GoTextFace{
 						Source: textSource,
 						Size:   36,
 					}, op)
+
+					// who is playing the section
+					op = &text.DrawOptions{}
+					op.GeoM.Translate(float64(startX+(uiSectionWidth/3)), float64(startY+18+48))
+					op.ColorScale.ScaleWithColor(color.White)
+					text.Draw(screen, subtitle, &text.GoTextFace{
+						Source: textSource,
+						Size:   24,
+					}, op)
 				}
 				index++
 			}
 		}
 
+		// the board size row, under the sections it lines up with
+		uiBoardRowHeight := newGameBoardRowHeight
+		columnsLabel, rowsLabel := boardSizeLabels(g)
+		for i, label := range []string{columnsLabel, rowsLabel} {
+			startX := newGameSection(g, newGameColumnsCell+i).Min.X
+			cell := ebiten.NewImage(uiSectionWidth, uiBoardRowHeight)
+			cell.Fill(uiIncludedColor)
+			if g.uiNewGameSectionHighlighted == newGameColumnsCell+i {
+				cell.Fill(uiHighlightColor)
+			}
+			cellDo := &ebiten.DrawImageOptions{}
+			cellDo.GeoM.Translate(float64(startX), float64(uiMessageBoxStartY))
+			screen.DrawImage(cell, cellDo)
+
+			op := &text.DrawOptions{}
+			op.GeoM.Translate(float64(startX+(uiSectionWidth/3)), float64(uiMessageBoxStartY+8))
+			op.ColorScale.ScaleWithColor(color.White)
+			text.Draw(screen, label, &text.GoTextFace{
+				Source: textSource,
+				Size:   24,
+			}, op)
+		}
+
 		// draw the message box section
-		op := &text.DrawOptions{}
-		op.GeoM.Translate(float64(uiBorder), float64(uiMessageBoxStartY))
-		op.ColorScale.ScaleWithColor(color.White)
-		text.Draw(screen, "Use the arrow keys to navigate", &text.GoTextFace{
-			Source: textSource,
-			Size:   36,
-		}, op)
-		op = &text.DrawOptions{}
-		op.GeoM.Translate(float64(uiBorder), float64(uiMessageBoxStartY+36+8))
-		op.ColorScale.ScaleWithColor(color.White)
-		text.Draw(screen, "Spacebar to toggle players", &text.GoTextFace{
-			Source: textSource,
-			Size:   36,
-		}, op)
+		messages := []string{"Use the arrow keys to navigate", "Space toggles players or grows the board", "Enter switches human or bot, or shrinks it"}
+		messageSize := 24
+		if inLobby(g) {
+			// the room picked the board, so there is only the room to talk about
+			messages = lobbyMessages(g)
+		}
+		for i, message := range messages {
+			op := &text.DrawOptions{}
+			op.GeoM.Translate(float64(uiBorder), float64(uiMessageBoxStartY+uiBoardRowHeight+8+(messageSize+8)*i))
+			op.ColorScale.ScaleWithColor(color.White)
+			text.Draw(screen, message, &text.GoTextFace{
+				Source: textSource,
+				Size:   float64(messageSize),
+			}, op)
+		}
 
-		newGameButton := ebiten.NewImage(g.screenSize.X-(uiBorder*2), uiStartGameAreaHeight-uiBorder)
+		uiStartButton := newGameStartButton(g)
+		newGameButton := ebiten.NewImage(uiStartButton.Dx(), uiStartButton.Dy())
 		if g.uiStartNewGameButton {
 			newGameButton.Fill(uiHighlightColor)
 		} else {
@@ -1068,18 +930,170 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		}
 
 		stargGameDo := &ebiten.DrawImageOptions{}
-		stargGameDo.GeoM.Translate(float64(uiBorder), float64(g.screenSize.Y-uiStartGameAreaHeight))
+		stargGameDo.GeoM.Translate(float64(uiStartButton.Min.X), float64(uiStartButton.Min.Y))
 		screen.DrawImage(newGameButton, stargGameDo)
 
-		op = &text.DrawOptions{}
-		op.GeoM.Translate(float64(g.screenSize.X/3), float64(g.screenSize.Y-uiStartGameAreaHeight+18))
+		startLabel := "Start Game"
+		if inLobby(g) && !g.network.lobby.Host {
+			startLabel = "Host starts"
+		}
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(g.screenSize.X/3), float64(uiStartButton.Min.Y+18))
 		op.ColorScale.ScaleWithColor(color.White)
-		text.Draw(screen, "Start Game", &text.GoTextFace{
+		text.Draw(screen, startLabel, &text.GoTextFace{
 			Source: textSource,
 			Size:   36,
 		}, op)
+	} else if g.gameState == 4 {
+		// save slot picker
+		title := "Load Game"
+		if g.uiSaveSlotSaving {
+			title = "Save Game"
+		}
+		message := "'space' to pick a slot, 'esc' to go back"
+		if g.uiSaveSlotMessage != "" {
+			message = g.uiSaveSlotMessage
+		}
+		drawListMenue(g, screen, textSource, title, g.uiSaveSlotLabels, g.uiSaveSlotSelected, message)
+	} else if g.gameState == 5 {
+		// settings
+		drawListMenue(g, screen, textSource, "Settings", settingsLabels(g), g.uiSettingsSelected, "'left' and 'right' to change, 'esc' to go back")
+	} else if g.gameState == 6 {
+		// results of the finished game
+		drawListMenue(g, screen, textSource, "Game Over", resultsLabels(g), -1, "'space' for a new game, 'esc' for the menue")
+	} else if g.gameState == 7 {
+		// replay picker
+		message := "'space' to watch a replay, 'esc' to go back"
+		if g.uiReplayMessage != "" {
+			message = g.uiReplayMessage
+		}
+		drawListMenue(g, screen, textSource, "Replays", replayLabels(g), g.uiReplaySelected, message)
+	} else if g.gameState == 8 {
+		// watching a replay
+		drawReplay(g, screen, textSource)
+	} else if g.gameState == 9 {
+		// creating or joining a room on the server
+		message := "'space' to pick, type the code to join, 'esc' to go back"
+		if g.uiOnlineMessage != "" {
+			message = g.uiOnlineMessage
+		}
+		drawListMenue(g, screen, textSource, "Online", onlineLabels(g), g.uiOnlineSelected, message)
+	} else if g.gameState == 10 {
+		// changing the keys of each action
+		message := "'space' to change, 'enter' to reset, 'esc' to go back"
+		if g.uiBindingsMessage != "" {
+			message = g.uiBindingsMessage
+		}
+		drawListMenue(g, screen, textSource, "Key Bindings", bindingsLabels(g), g.uiBindingsSelected, message)
+	}
+
+}
+
+// drawBoardTiles draws the checkerboard for the board
+func drawBoardTiles(screen *ebiten.Image, board rules.Board, tileSize int) {
+	//loop through the tiles of the board
+	for x := 0; x <= board.Width; x++ {
+		for y := 0; y <= board.Height; y++ {
+			xPos := x * tileSize
+			yPos := y * tileSize
+
+			// Draw the board checkerboard black and white squares filled
+			if (x+y)%2 == 0 {
+				// Using tileSize as the size of each square draw a black square
+				vector.DrawFilledRect(screen, float32(xPos), float32(yPos),
+					float32(tileSize), float32(tileSize), color.White, true)
+			} else {
+				vector.DrawFilledRect(screen, float32(xPos), float32(yPos),
+					float32(tileSize), float32(tileSize), color.Black, true)
+			}
+		}
+	}
+}
+
+// drawPieces draws every players pieces, with their values
+func drawPieces(screen *ebiten.Image, state rules.State, tileSize int, textSource *text.GoTextFaceSource) {
+	for _, player := range state.Players {
+		for _, piece := range player.Pieces {
+			// update the board with the piece position
+			xPos := piece.Position.X * tileSize
+			yPos := piece.Position.Y * tileSize
+
+			pieceBox := ebiten.NewImage(tileSize/2, tileSize/2)
+			pieceBox.Fill(piece.Color)
+
+			// Draw the Piece box for player colour
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(xPos+tileSize/4), float64(yPos+tileSize/4))
+			screen.DrawImage(pieceBox, op)
+
+			// Draw the Piece value
+			opT := &text.DrawOptions{}
+			opT.GeoM.Translate(float64(xPos+tileSize*9/20), float64(yPos+tileSize*7/20)) // note had to manually find the center based on 18 as the font size
+			opT.ColorScale.ScaleWithColor(color.White)
+			text.Draw(screen, fmt.Sprint(piece.Value), &text.GoTextFace{
+				Source: textSource,
+				Size:   18,
+			}, opT)
+		}
+	}
+}
+
+// drawListMenue draws a titled list of rows, used by screens with too much text for drawMenueButton
+func drawListMenue(g *Game, screen *ebiten.Image, textSource *text.GoTextFaceSource, title string, labels []string, selected int, message string) {
+	uiBox := listMenueBox(g)
+	uiBackgroundColor := color.RGBA{0x55, 0x55, 0x55, 0x55}
+	uiButtonColor := color.RGBA{0x33, 0x33, 0x33, 0xff}
+	uiButtonHighlightColor := color.RGBA{0x88, 0x88, 0x88, 0xff}
+
+	// Draw the ui menue background box
+	menueBox := ebiten.NewImage(uiBox.Dx(), uiBox.Dy())
+	menueBox.Fill(uiBackgroundColor)
+	menueDo := &ebiten.DrawImageOptions{}
+	menueDo.GeoM.Translate(float64(uiBox.Min.X), float64(uiBox.Min.Y))
+	screen.DrawImage(menueBox, menueDo)
+
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64(listMenueBorder+listMenueButtonBorder), float64(listMenueBorder+listMenueButtonBorder))
+	op.ColorScale.ScaleWithColor(color.White)
+	text.Draw(screen, title, &text.GoTextFace{
+		Source: textSource,
+		Size:   listMenueTitleHeight,
+	}, op)
+
+	// the rows get shorter for long lists, see listMenueRow
+	messageY := listMenueRow(g, len(labels), 0).Min.Y
+	for i, label := range labels {
+		row := listMenueRow(g, len(labels), i)
+		buttonBox := ebiten.NewImage(row.Dx(), row.Dy())
+		if selected == i {
+			buttonBox.Fill(uiButtonHighlightColor)
+		} else {
+			buttonBox.Fill(uiButtonColor)
+		}
+		buttonDo := &ebiten.DrawImageOptions{}
+		buttonDo.GeoM.Translate(float64(row.Min.X), float64(row.Min.Y))
+		screen.DrawImage(buttonBox, buttonDo)
+
+		// the labels are longer than menue buttons, so use a smaller font from the left edge
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(float64(row.Min.X+listMenueButtonBorder), float64(row.Min.Y+(row.Dy()-26)/2))
+		op.ColorScale.ScaleWithColor(color.White)
+		text.Draw(screen, label, &text.GoTextFace{
+			Source: textSource,
+			Size:   24,
+		}, op)
+
+		messageY = row.Max.Y + listMenueButtonBorder
 	}
 
+	// show the instructions, or the reason the last action failed
+	op = &text.DrawOptions{}
+	op.GeoM.Translate(float64(listMenueBorder+listMenueButtonBorder), float64(messageY))
+	op.ColorScale.ScaleWithColor(color.White)
+	text.Draw(screen, message, &text.GoTextFace{
+		Source: textSource,
+		Size:   18,
+	}, op)
 }
 
 func drawMenueButton(screen *ebiten.Image, startX, startY, width, height int, buttonColor color.Color, s *text.GoTextFaceSource, buttonText string) {
@@ -1099,6 +1113,36 @@ func drawMenueButton(screen *ebiten.Image, startX, startY, width, height int, bu
 	}, op)
 }
 
+// drawPlayButton draws one of the small buttons under the board
+func drawPlayButton(screen *ebiten.Image, button image.Rectangle, buttonColor color.Color, s *text.GoTextFaceSource, buttonText string) {
+	buttonBox := ebiten.NewImage(button.Dx(), button.Dy())
+	buttonBox.Fill(buttonColor)
+	buttonDo := &ebiten.DrawImageOptions{}
+	buttonDo.GeoM.Translate(float64(button.Min.X), float64(button.Min.Y))
+	screen.DrawImage(buttonBox, buttonDo)
+
+	op := &text.DrawOptions{}
+	op.GeoM.Translate(float64(button.Min.X+10), float64(button.Min.Y+(button.Dy()-18)/2))
+	op.ColorScale.ScaleWithColor(color.White)
+	text.Draw(screen, buttonText, &text.GoTextFace{
+		Source: s,
+		Size:   18,
+	}, op)
+}
+
+// isControlPressed checks for either control key, or command on a mac
+func isControlPressed() bool {
+	return ebiten.IsKeyPressed(ebiten.KeyControl) || ebiten.IsKeyPressed(ebiten.KeyMeta)
+}
+
+// openSaveSlotPicker shows the save slots, with what is currently stored in each
+func openSaveSlotPicker(g *Game, saving bool) {
+	g.uiSaveSlotSaving = saving
+	g.uiSaveSlotLabels = saveSlotLabels()
+	g.uiSaveSlotMessage = ""
+	g.gameState = 4
+}
+
 // Layout takes the outside size (in device-independent pixels) and returns the logical screen size.
 // If you don't have to adjust the screen size with the outside size, just return a fixed size.
 func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
@@ -1117,41 +1161,31 @@ $Env:GOOS = "windows"; $Env:GOARCH = "amd64"; go build -o windows.exe main.go 	/
 $Env:GOOS = "js"; $Env:GOARCH = "wasm"; go build -o browser.wasm main.go 		// browser
 */
 func main() {
+	settings := loadSettings()
+
 	g := &Game{
-		keyStates:                   make(map[ebiten.Key]bool),
-		board:                       createBoard(7, 7, 80), // 8 by 8 tiles
-		players:                     createPlayers([]int{-1, 2, 1, -1}),
-		turn:                        0,
-		HighlightedTile:             Position{-1, -1},
-		SelectedTile:                Position{X: -1, Y: -1},
-		GameOver:                    false,
+		state:                       rules.NewGame(settings.BoardWidth, settings.BoardHeight, settings.Seats),
+		HighlightedTile:             rules.NoPosition,
+		SelectedTile:                rules.NoPosition,
+		InvalidTile:                 rules.NoPosition,
+		uiTargetFrom:                rules.NoPosition,
+		uiTargetTile:                rules.NoPosition,
 		gameState:                   0,
 		uiMenueSelectedButton:       0,
-		uiMenueButtonNumber:         5,
+		uiMenueButtonNumber:         7,
 		uiNewGameConfirmation:       false,
-		uiNewGameSectionPlayer:      make([]int, 4),
+		uiNewGameSectionPlayer:      append([]int(nil), settings.Seats...),
 		uiNewGameSectionHighlighted: 0,
+		uiNewGameSectionBot:         make([]string, len(settings.Seats)),
 		uiStartNewGameButton:        false,
-		screenSize:                  Position{640, 720}, //960, 720
+		settings:                    settings,
 	}
 
 	//setup game
-	setPiecesOnBoardFromPlayers(g)
-	updatePlayerActions(g)
-	for i := range g.uiNewGameSectionPlayer {
-		switch i {
-		case 0:
-			g.uiNewGameSectionPlayer[i] = -1
-		case 1:
-			g.uiNewGameSectionPlayer[i] = 2
-		case 2:
-			g.uiNewGameSectionPlayer[i] = 1
-		case 3:
-			g.uiNewGameSectionPlayer[i] = -1
-		}
-	}
+	startRecording(g, settings.BoardWidth, settings.BoardHeight, settings.Seats)
+	applySettings(g)
+	focusCurrentPlayer(g)
 
-	ebiten.SetWindowSize(g.screenSize.X, g.screenSize.Y)
 	ebiten.SetWindowTitle("Six Divides")
 	if err := ebiten.RunGame(g); err != nil {
 		log.Fatal(err)
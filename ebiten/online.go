@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"sixDivides/notation"
+	"sixDivides/online"
+	"sixDivides/rules"
+)
+
+var errNotYourTurn = errors.New("it is not your turn")
+
+// reconnectDelay is the wait between attempts to connect again after losing the connection
+const reconnectDelay = 2 * time.Second
+
+// onlineSessionFileName keeps the last online player, to take it back after restarting the game
+const onlineSessionFileName = "online.json"
+
+// networkGame is the connection to the server while playing online. The
+// connection is run by its own goroutines, which pass messages to and from
+// Update through the channels.
+type networkGame struct {
+	out    chan online.ClientMessage
+	in     chan online.ServerMessage
+	cancel context.CancelFunc
+	// player is the index of the player played at this computer, or online.Spectator
+	player int
+	// room is the join code of the room
+	room string
+	// lobby is the room before its game starts, it is nil once the game has started
+	lobby *online.Lobby
+	// status is shown under the board
+	status string
+}
+
+// connectOnline starts connecting to the server, sending the first message to
+// create or join a room once it is connected
+func connectOnline(g *Game, first online.ClientMessage) {
+	disconnectOnline(g)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &networkGame{
+		out:    make(chan online.ClientMessage, 16),
+		in:     make(chan online.ServerMessage, 64),
+		cancel: cancel,
+		player: online.Spectator,
+		status: "connecting to " + g.settings.ServerURL,
+	}
+	first.Name = g.settings.PlayerName
+	g.network = n
+	go n.run(ctx, g.settings.ServerURL, first)
+}
+
+// disconnectOnline closes the connection, leaving the game as it was to carry on locally
+func disconnectOnline(g *Game) {
+	if g.network == nil {
+		return
+	}
+	g.network.cancel()
+	g.network = nil
+}
+
+// run keeps the client connected to the server until it is cancelled. Once
+// in a game it connects again whenever the connection is lost, and takes its
+// player back with the token.
+func (n *networkGame) run(ctx context.Context, url string, first online.ClientMessage) {
+	var rejoin online.ClientMessage
+	for {
+		if joined := n.connection(ctx, url, first); joined.Token != "" {
+			rejoin = joined
+		}
+		if ctx.Err() != nil || rejoin.Token == "" {
+			return
+		}
+
+		n.receive(ctx, online.ServerMessage{Type: online.TypeNotice, Player: online.Spectator, Notice: "lost connection to the server, trying again"})
+		select {
+		case <-time.After(reconnectDelay):
+		case <-ctx.Done():
+			return
+		}
+		first = rejoin
+	}
+}
+
+// connection connects to the server, then passes messages until the connection
+// is lost or cancelled. It returns the message to join as the same player again,
+// which has no token when the client never got a player.
+func (n *networkGame) connection(ctx context.Context, url string, first online.ClientMessage) online.ClientMessage {
+	var rejoin online.ClientMessage
+	conn, _, err := websocket.Dial(ctx, url, nil)
+	if err != nil {
+		if ctx.Err() == nil {
+			n.receive(ctx, online.ServerMessage{Type: online.TypeError, Player: online.Spectator, Error: "could not connect to the server"})
+			log.Printf("could not connect to %v: %v", url, err)
+		}
+		return rejoin
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(online.MaxMessageSize)
+	if err := wsjson.Write(ctx, conn, first); err != nil {
+		return rejoin
+	}
+
+	// the writer stops with the connection, leaving anything still queued for the next one
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		for {
+			select {
+			case msg := <-n.out:
+				if err := wsjson.Write(connCtx, conn, msg); err != nil {
+					conn.CloseNow()
+					return
+				}
+			case <-connCtx.Done():
+				conn.Close(websocket.StatusNormalClosure, "")
+				return
+			}
+		}
+	}()
+
+	for {
+		var msg online.ServerMessage
+		if err := wsjson.Read(ctx, conn, &msg); err != nil {
+			if ctx.Err() == nil {
+				log.Printf("lost connection to the server: %v", err)
+				if rejoin.Token == "" {
+					n.receive(ctx, online.ServerMessage{Type: online.TypeError, Player: online.Spectator, Error: "lost connection to the server"})
+				}
+			}
+			return rejoin
+		}
+		if msg.Type == online.TypeJoined && msg.Token != "" {
+			rejoin = online.ClientMessage{Type: online.TypeJoin, Room: msg.Room, Token: msg.Token}
+		}
+		n.receive(ctx, msg)
+	}
+}
+
+// receive passes the message on to Update, unless the connection has been closed
+func (n *networkGame) receive(ctx context.Context, msg online.ServerMessage) {
+	select {
+	case n.in <- msg:
+	case <-ctx.Done():
+	}
+}
+
+// tickOnline is called every frame, and handles the messages that have come in from the server
+func tickOnline(g *Game) {
+	for g.network != nil {
+		select {
+		case msg := <-g.network.in:
+			handleServerMessage(g, msg)
+		default:
+			return
+		}
+	}
+}
+
+func handleServerMessage(g *Game, msg online.ServerMessage) {
+	n := g.network
+	switch msg.Type {
+	case online.TypeLobby:
+		n.room = msg.Room
+		n.lobby = msg.Lobby
+		n.status = fmt.Sprintf("in room %v, waiting for the host to start", msg.Room)
+		if g.gameState == 9 {
+			// show the lobby on the new game screen
+			g.uiNewGameSectionHighlighted = 0
+			g.uiStartNewGameButton = false
+			g.gameState = 3
+		}
+	case online.TypeJoined:
+		record, err := notation.ParseRecord(msg.Record)
+		if err != nil {
+			n.status = "could not read the game from the server"
+			log.Printf("could not read the game from the server: %v", err)
+			return
+		}
+		state, err := record.Replay()
+		if err != nil {
+			n.status = "could not read the game from the server"
+			log.Printf("could not replay the game from the server: %v", err)
+			return
+		}
+
+		g.state = state
+		resumeRecording(g, record)
+		setBots(g, nil)
+		clearHistory(g)
+		g.InvalidTile = rules.NoPosition
+		fitTileSize(g)
+		focusCurrentPlayer(g)
+		showResultsIfOver(g)
+
+		n.room = msg.Room
+		n.lobby = nil
+		n.player = msg.Player
+		if msg.Token != "" {
+			saveOnlineSession(onlineSession{ServerURL: g.settings.ServerURL, Room: msg.Room, Token: msg.Token})
+		}
+		if g.gameState == 3 || g.gameState == 9 {
+			g.gameState = 0
+		}
+		if n.player == online.Spectator {
+			n.status = fmt.Sprintf("watching room %v", msg.Room)
+		} else {
+			n.status = fmt.Sprintf("in room %v as %v", msg.Room, g.state.Players[n.player].Name)
+		}
+	case online.TypeMoved:
+		m, err := notation.ParseMove(msg.Move)
+		if err == nil {
+			err = applyMove(g, m)
+		}
+		if err != nil {
+			// the rules are the same on both sides, so this should only happen with a different version of the game
+			n.status = "out of step with the server, go online again to catch up"
+			log.Printf("could not apply %v from the server: %v", msg.Move, err)
+		}
+	case online.TypeNotice:
+		n.status = msg.Notice
+	case online.TypeError:
+		n.status = msg.Error
+		if g.gameState == 9 && n.room == "" {
+			// could not get into a room, so there is nothing to stay connected for
+			g.uiOnlineMessage = msg.Error
+			disconnectOnline(g)
+		}
+		log.Printf("server: %v", msg.Error)
+	}
+}
+
+// sendOnlineMove checks the move against the local copy of the game, so
+// mistakes show straight away, and then asks the server to play it
+func sendOnlineMove(g *Game, m rules.Move) error {
+	return sendOnlineMoves(g, []rules.Move{m})
+}
+
+// sendOnlineMoves checks the moves one after the other, as the local copy
+// only changes once the server sends them back, and then sends them all
+func sendOnlineMoves(g *Game, moves []rules.Move) error {
+	if g.network.player != g.state.Turn {
+		return errNotYourTurn
+	}
+	if len(moves) > cap(g.network.out)-len(g.network.out) {
+		return errors.New("still sending the last messages to the server")
+	}
+	s := g.state
+	var texts []string
+	for _, m := range moves {
+		n, result, err := rules.Apply(s, m)
+		if err != nil {
+			return err
+		}
+		s = n
+		m.Kind = result.Kind
+		text, err := notation.FormatMove(m)
+		if err != nil {
+			return err
+		}
+		texts = append(texts, text)
+	}
+
+	for _, text := range texts {
+		if err := sendOnline(g, online.ClientMessage{Type: online.TypeMove, Move: text}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sendOnline queues the message for the server
+func sendOnline(g *Game, msg online.ClientMessage) error {
+	select {
+	case g.network.out <- msg:
+		return nil
+	default:
+		return errors.New("still sending the last messages to the server")
+	}
+}
+
+// onlineSession is the player last played online, kept so it can be rejoined
+type onlineSession struct {
+	ServerURL string `json:"serverURL"`
+	Room      string `json:"room"`
+	Token     string `json:"token"`
+}
+
+func saveOnlineSession(session onlineSession) {
+	data, err := json.MarshalIndent(session, "", "\t")
+	if err == nil {
+		err = writeStorage(onlineSessionFileName, data)
+	}
+	if err != nil {
+		log.Printf("could not keep the online session: %v", err)
+	}
+}
+
+// loadOnlineSession reads the last online player, the room is empty when there is none
+func loadOnlineSession() onlineSession {
+	var session onlineSession
+	data, err := readStorage(onlineSessionFileName)
+	if err != nil {
+		return session
+	}
+	if err := json.Unmarshal(data, &session); err != nil {
+		log.Printf("could not read the online session: %v", err)
+		return onlineSession{}
+	}
+	return session
+}
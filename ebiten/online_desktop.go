@@ -0,0 +1,8 @@
+//go:build !js
+
+package main
+
+// defaultServerURL is the server started by server/main.go on this computer
+func defaultServerURL() string {
+	return "ws://localhost:9090/ws"
+}
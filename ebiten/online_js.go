@@ -0,0 +1,22 @@
+//go:build js
+
+package main
+
+import (
+	"syscall/js"
+
+	"sixDivides/online"
+)
+
+// defaultServerURL is the server the page was loaded from
+func defaultServerURL() string {
+	location := js.Global().Get("location")
+	host := location.Get("host").String()
+	switch location.Get("protocol").String() {
+	case "https:":
+		return "wss://" + host + online.Path
+	case "http:":
+		return "ws://" + host + online.Path
+	}
+	return "ws://localhost:9090" + online.Path
+}
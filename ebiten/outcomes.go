@@ -0,0 +1,106 @@
+package main
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"sixDivides/rules"
+)
+
+// tileOutcome is what the selected piece would do to a neighbouring tile, the
+// kind is rules.KindAny when it can not act on it
+type tileOutcome struct {
+	tile rules.Position
+	kind rules.MoveKind
+}
+
+// outcomeKinds are the outcomes in the order the legend lists them, with the
+// colour each tile is framed in
+var outcomeKinds = []struct {
+	kind  rules.MoveKind
+	label string
+	color color.RGBA
+}{
+	{rules.KindMove, "move", color.RGBA{0x44, 0xaa, 0xff, 0xff}},
+	{rules.KindSpawn, "spawn", color.RGBA{0x66, 0xdd, 0x66, 0xff}},
+	{rules.KindMerge, "merge", color.RGBA{0x00, 0xcc, 0xcc, 0xff}},
+	{rules.KindSplit, "split to 6", color.RGBA{0xbb, 0x66, 0xff, 0xff}},
+	{rules.KindReinforce, "reinforce", color.RGBA{0xcc, 0xcc, 0x00, 0xff}},
+	{rules.KindAttack, "attack", color.RGBA{0xff, 0x88, 0x00, 0xff}},
+	{rules.KindOutpostStrike, "outpost strike", color.RGBA{0xff, 0x44, 0xaa, 0xff}},
+	{rules.KindAny, "invalid", color.RGBA{0x66, 0x66, 0x66, 0xff}},
+}
+
+// tileOutcomes works out what the selected piece would do to each of its
+// neighbouring tiles. It asks rules.Classify, the same as the arrow keys do
+// through rules.Apply when they act on a tile, so what is shown is what happens.
+func tileOutcomes(g *Game) []tileOutcome {
+	if g.SelectedTile == rules.NoPosition || !isLocalTurn(g) || g.state.GameOver {
+		return nil
+	}
+
+	var outcomes []tileOutcome
+	for _, d := range rules.Directions {
+		tile := rules.Position{X: g.SelectedTile.X + d.X, Y: g.SelectedTile.Y + d.Y}
+		if !g.state.Board.InBounds(tile) {
+			continue
+		}
+		kind, err := rules.Classify(g.state, g.SelectedTile, tile)
+		if err != nil {
+			kind = rules.KindAny
+		}
+		outcomes = append(outcomes, tileOutcome{tile: tile, kind: kind})
+	}
+	return outcomes
+}
+
+// drawOutcomes frames each neighbouring tile of the selected piece in the colour of what it would do there
+func drawOutcomes(g *Game, screen *ebiten.Image) {
+	for _, outcome := range tileOutcomes(g) {
+		for _, k := range outcomeKinds {
+			if k.kind != outcome.kind {
+				continue
+			}
+			x := float32(outcome.tile.X*g.tileSize + 2)
+			y := float32(outcome.tile.Y*g.tileSize + 2)
+			size := float32(g.tileSize - 4)
+			vector.StrokeRect(screen, x, y, size, size, 4, k.color, false)
+		}
+	}
+}
+
+// drawOutcomeLegend lists the colours framing the tiles around the selected
+// piece and what each means, on the line at x, y. It is false when there is
+// no piece selected, so nothing was drawn.
+func drawOutcomeLegend(g *Game, screen *ebiten.Image, textSource *text.GoTextFaceSource, x, y int) bool {
+	outcomes := tileOutcomes(g)
+	if len(outcomes) == 0 {
+		return false
+	}
+
+	// a little smaller than the other lines, so four of them fit beside the buttons
+	face := &text.GoTextFace{
+		Source: textSource,
+		Size:   16,
+	}
+	left := float64(x)
+	for _, k := range outcomeKinds {
+		shown := false
+		for _, outcome := range outcomes {
+			shown = shown || outcome.kind == k.kind
+		}
+		if !shown {
+			continue
+		}
+
+		vector.DrawFilledRect(screen, float32(left), float32(y+4), 12, 12, k.color, false)
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(left+18, float64(y))
+		op.ColorScale.ScaleWithColor(color.White)
+		text.Draw(screen, k.label, face, op)
+		left += 18 + text.Advance(k.label, face) + 12
+	}
+	return true
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"sixDivides/rules"
+)
+
+// targeting is true while the cursor is picking a tile for the selected piece
+// to go to, rather than the arrows acting on the tiles next to it. It stops
+// as soon as another piece is selected, or the selection is cleared.
+func targeting(g *Game) bool {
+	return g.uiTargeting && g.SelectedTile != rules.NoPosition && g.SelectedTile == g.uiTargetFrom && isLocalTurn(g) && !g.state.GameOver
+}
+
+// startTargeting lets the cursor move freely from the selected piece, picking
+// a highlighted piece of their own first when nothing is selected
+func startTargeting(g *Game) {
+	if !isLocalTurn(g) || g.state.GameOver {
+		return
+	}
+	if g.SelectedTile == rules.NoPosition {
+		g.handleAction(actionSelect)
+		if g.SelectedTile == rules.NoPosition {
+			return
+		}
+	}
+	g.uiTargeting = true
+	g.uiTargetFrom = g.SelectedTile
+	g.HighlightedTile = g.SelectedTile
+}
+
+// stopTargeting puts the cursor back on the selected piece
+func stopTargeting(g *Game) {
+	g.uiTargeting = false
+	if g.SelectedTile != rules.NoPosition {
+		g.HighlightedTile = g.SelectedTile
+	}
+}
+
+// handleTargetAction moves the cursor while targeting, and goes to the tile
+// under it on select. It is false for the actions that work as normal.
+func handleTargetAction(g *Game, a action) bool {
+	move := rules.Position{}
+	switch a {
+	case actionCursorUp:
+		move.Y = -1
+	case actionCursorDown:
+		move.Y = 1
+	case actionCursorLeft:
+		move.X = -1
+	case actionCursorRight:
+		move.X = 1
+	case actionSelect:
+		target := g.HighlightedTile
+		if target == g.SelectedTile {
+			// picking the piece itself is a change of mind
+			stopTargeting(g)
+			return true
+		}
+		g.uiTargeting = false
+		if err := goToTile(g, target); err != nil {
+			log.Printf("can not go to %d, %d: %v", target.X, target.Y, err)
+			g.InvalidTile = target
+			stopTargeting(g)
+		}
+		return true
+	case actionOpenMenu, actionTarget:
+		stopTargeting(g)
+		return true
+	default:
+		return false
+	}
+
+	next := rules.Position{X: g.HighlightedTile.X + move.X, Y: g.HighlightedTile.Y + move.Y}
+	if g.state.Board.InBounds(next) {
+		g.HighlightedTile = next
+	}
+	return true
+}
+
+// targetTile is the tile the path shown on the board goes to, the cursor while
+// targeting, or the tile under the mouse while a piece is selected
+func targetTile(g *Game) (rules.Position, bool) {
+	if g.SelectedTile == rules.NoPosition || g.SelectedTile != g.uiTargetFrom || !isLocalTurn(g) || g.state.GameOver {
+		return rules.NoPosition, false
+	}
+	target := g.uiTargetTile
+	if targeting(g) {
+		target = g.HighlightedTile
+	}
+	return target, target != rules.NoPosition && target != g.SelectedTile
+}
+
+// goToTile plays the moves of the shortest path from the selected piece to the
+// tile, one action for each step. It has to be done in one go, so it is not
+// started without the actions for all of it.
+func goToTile(g *Game, target rules.Position) error {
+	path, err := rules.FindPath(g.state, g.SelectedTile, target)
+	if err != nil {
+		return err
+	}
+	if actions := g.state.CurrentPlayer().Actions; len(path) > actions {
+		return fmt.Errorf("the path takes %v actions, there are %v left", len(path), actions)
+	}
+	if g.network != nil {
+		return sendOnlineMoves(g, path)
+	}
+
+	// check the whole path before playing any of it, so a failure does not stop half way
+	s := g.state
+	for _, m := range path {
+		if s, _, err = rules.Apply(s, m); err != nil {
+			return err
+		}
+	}
+	for _, m := range path {
+		if err := applyMove(g, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pathMessage describes the path to the target tile, for under the board
+func pathMessage(g *Game) (string, bool) {
+	target, ok := targetTile(g)
+	if !ok {
+		if targeting(g) {
+			return fmt.Sprintf("Pick a tile to go to, '%v' to go, '%v' to stop", actionKeyName(g, actionSelect), actionKeyName(g, actionOpenMenu)), true
+		}
+		return "", false
+	}
+	path, err := rules.FindPath(g.state, g.SelectedTile, target)
+	if err != nil {
+		return fmt.Sprintf("Can not go there: %v", err), true
+	}
+	confirm := "click to go"
+	if targeting(g) {
+		confirm = fmt.Sprintf("'%v' to go", actionKeyName(g, actionSelect))
+	}
+	actions := g.state.CurrentPlayer().Actions
+	if len(path) > actions {
+		confirm = "not enough actions"
+	}
+	return fmt.Sprintf("Path: %v of %v actions, %v", len(path), actions, confirm), true
+}
+
+// drawPath shades the tiles the selected piece would go through to reach the
+// target tile, in red when there are not the actions for all of it
+func drawPath(g *Game, screen *ebiten.Image) {
+	target, ok := targetTile(g)
+	if !ok {
+		return
+	}
+	path, err := rules.FindPath(g.state, g.SelectedTile, target)
+	if err != nil {
+		return
+	}
+
+	stepColor := color.RGBA{0x00, 0x88, 0xcc, 0xaa}
+	if len(path) > g.state.CurrentPlayer().Actions {
+		stepColor = color.RGBA{0xcc, 0x22, 0x22, 0xaa}
+	}
+	var boaderSize = 5
+	for _, m := range path {
+		x := float32(m.To.X*g.tileSize + boaderSize)
+		y := float32(m.To.Y*g.tileSize + boaderSize)
+		size := float32(g.tileSize - boaderSize*2)
+		vector.DrawFilledRect(screen, x, y, size, size, stepColor, false)
+	}
+}
+
+// nextTo is true for neighbouring tiles, the ones a piece can act on
+func nextTo(a, b rules.Position) bool {
+	dx, dy := a.X-b.X, a.Y-b.Y
+	return dx*dx+dy*dy == 1
+}
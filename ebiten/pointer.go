@@ -0,0 +1,240 @@
+package main
+
+import (
+	"image"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"sixDivides/rules"
+)
+
+// tickPointer is called every frame, and handles the mouse and touches. The
+// pointer highlights whatever it is over, the same as the arrow keys would,
+// and a click or tap then does the action for it, so both take the same path
+// through handleAction. The right mouse button opens the menue or goes back.
+func tickPointer(g *Game) {
+	x, y := ebiten.CursorPosition()
+	cursor := image.Pt(x, y)
+	// only a mouse that moved takes the highlight, so it does not fight the keys
+	if cursor != g.uiPointer {
+		g.uiPointer = cursor
+		hoverPointer(g, cursor)
+	}
+
+	touches := inpututil.AppendJustPressedTouchIDs(nil)
+	for _, id := range touches {
+		x, y := ebiten.TouchPosition(id)
+		g.uiPointerUsed = true
+		pressPointer(g, image.Pt(x, y))
+	}
+	// a browser can also send a tap as a click, so it is only counted once
+	if len(touches) > 0 {
+		return
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		g.uiPointerUsed = true
+		pressPointer(g, cursor)
+	}
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
+		g.handleAction(actionOpenMenu)
+	}
+}
+
+// pointerTile is the board tile under the pointer
+func pointerTile(g *Game, p image.Point) (rules.Position, bool) {
+	if g.tileSize <= 0 || p.X < 0 || p.Y < 0 {
+		return rules.NoPosition, false
+	}
+	tile := rules.Position{X: p.X / g.tileSize, Y: p.Y / g.tileSize}
+	return tile, g.state.Board.InBounds(tile)
+}
+
+// listMenueSelection is the number of rows and the selected row of the list menue being shown
+func listMenueSelection(g *Game) (int, *int, bool) {
+	switch g.gameState {
+	case 4:
+		return len(g.uiSaveSlotLabels), &g.uiSaveSlotSelected, true
+	case 5:
+		return settingsRowCount, &g.uiSettingsSelected, true
+	case 7:
+		return len(replayLabels(g)), &g.uiReplaySelected, true
+	case 9:
+		return onlineRowCount, &g.uiOnlineSelected, true
+	case 10:
+		return bindingsRowCount, &g.uiBindingsSelected, true
+	}
+	return 0, nil, false
+}
+
+// hoverPointer highlights the tile, button or row under the pointer
+func hoverPointer(g *Game, p image.Point) {
+	switch g.gameState {
+	case 0:
+		tile, ok := pointerTile(g, p)
+		if !ok || !isLocalTurn(g) {
+			return
+		}
+		if targeting(g) || g.SelectedTile == rules.NoPosition {
+			g.HighlightedTile = tile
+		} else {
+			// with a piece selected the highlight is where the arrow keys act from, so it stays
+			// put, and the path to the tile under the pointer is shown instead
+			g.uiTargetFrom, g.uiTargetTile = g.SelectedTile, tile
+		}
+	case 1:
+		for i := 0; i <= g.uiMenueButtonNumber; i++ {
+			if p.In(pauseMenueButton(g, i)) {
+				g.uiMenueSelectedButton = i
+			}
+		}
+	case 2:
+		if p.In(confirmButton(g, false)) {
+			g.uiNewGameConfirmation = false
+		} else if p.In(confirmButton(g, true)) {
+			g.uiNewGameConfirmation = true
+		}
+	case 3:
+		for i := 0; i <= newGameRowsCell; i++ {
+			if p.In(newGameSection(g, i)) {
+				g.uiNewGameSectionHighlighted = i
+				g.uiStartNewGameButton = false
+			}
+		}
+		if p.In(newGameStartButton(g)) {
+			g.uiNewGameSectionHighlighted = -1
+			g.uiStartNewGameButton = true
+		}
+	default:
+		if count, selected, ok := listMenueSelection(g); ok {
+			for i := 0; i < count; i++ {
+				if p.In(listMenueRow(g, count, i)) {
+					*selected = i
+				}
+			}
+		}
+	}
+}
+
+// pressPointer highlights what was clicked or tapped, and does the action for it
+func pressPointer(g *Game, p image.Point) {
+	hoverPointer(g, p)
+
+	switch g.gameState {
+	case 0:
+		if p.In(playButton(g, true)) {
+			g.handleAction(actionOpenMenu)
+		} else if p.In(playButton(g, false)) && isLocalTurn(g) {
+			g.handleAction(actionEndTurn)
+		} else if tile, ok := pointerTile(g, p); ok {
+			pressTile(g, tile)
+		}
+	case 1:
+		for i := 0; i <= g.uiMenueButtonNumber; i++ {
+			if p.In(pauseMenueButton(g, i)) {
+				g.handleAction(actionSelect)
+				return
+			}
+		}
+	case 2:
+		if p.In(confirmButton(g, false)) || p.In(confirmButton(g, true)) {
+			g.handleAction(actionSelect)
+		}
+	case 3:
+		pressNewGame(g, p)
+	case 6, 8:
+		// the results and the replay only have the one thing to do
+		g.handleAction(actionSelect)
+	default:
+		count, _, ok := listMenueSelection(g)
+		if !ok {
+			return
+		}
+		if !p.In(listMenueBox(g)) {
+			// outside of the menue backs out of it
+			g.handleAction(actionOpenMenu)
+			return
+		}
+		for i := 0; i < count; i++ {
+			if p.In(listMenueRow(g, count, i)) {
+				g.handleAction(actionSelect)
+				return
+			}
+		}
+	}
+}
+
+// pressTile selects the piece on the tile, or has the selected piece go to it
+// along the shortest path and act on it
+func pressTile(g *Game, tile rules.Position) {
+	if !isLocalTurn(g) || g.state.GameOver {
+		return
+	}
+	if g.SelectedTile == rules.NoPosition || g.SelectedTile == tile {
+		// the same as space on the tile, select it or deselect it
+		g.HighlightedTile = tile
+		g.handleAction(actionSelect)
+		return
+	}
+
+	piece, own := g.state.PieceAt(tile)
+	own = own && piece.PlayerIndex == g.state.CurrentPlayer().PlayerIndex
+	if own && !nextTo(g.SelectedTile, tile) {
+		// another of their own pieces further away, so they want that one instead
+		g.SelectedTile, g.HighlightedTile = tile, tile
+		return
+	}
+	g.uiTargeting = false
+	err := goToTile(g, tile)
+	if err == nil {
+		return
+	}
+	if own {
+		// one next to it the selected one can not act on, so they want that one instead too
+		g.SelectedTile, g.HighlightedTile = tile, tile
+		return
+	}
+	log.Printf("invalid move to %d, %d: %v", tile.X, tile.Y, err)
+	g.InvalidTile = tile
+}
+
+// pressNewGame works the new game screen. The top half of a section toggles
+// the player like space, and the bottom half of a taken one switches human or
+// bot like enter, or ready in a room. The left half of a board size shrinks
+// it and the right half grows it.
+func pressNewGame(g *Game, p image.Point) {
+	if p.In(newGameStartButton(g)) {
+		g.handleAction(actionSelect)
+		return
+	}
+	for i := 0; i <= newGameRowsCell; i++ {
+		section := newGameSection(g, i)
+		if !p.In(section) {
+			continue
+		}
+
+		if i >= newGameColumnsCell {
+			if inLobby(g) {
+				// the room already has its board, and enter would mark you ready
+				return
+			}
+			if p.X < section.Min.X+section.Dx()/2 {
+				g.handleAction(actionEndTurn)
+			} else {
+				g.handleAction(actionSelect)
+			}
+			return
+		}
+
+		taken := g.uiNewGameSectionPlayer[i] != -1
+		if inLobby(g) {
+			taken = g.network.lobby.You == i
+		}
+		if taken && p.Y >= section.Min.Y+section.Dy()/2 {
+			g.handleAction(actionEndTurn)
+		} else {
+			g.handleAction(actionSelect)
+		}
+		return
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"sixDivides/notation"
+	"sixDivides/rules"
+)
+
+const replayDirectory = "replays"
+
+// replayListLength is the number of most recent replays shown in the replay picker
+const replayListLength = 7
+
+// replaySpeeds are the autoplay speeds in moves per second, enter cycles through them
+var replaySpeeds = []int{1, 2, 4, 8}
+
+// replayViewer is a loaded recording, and how far through it the viewer is
+type replayViewer struct {
+	record     notation.Record
+	states     []rules.State
+	index      int   // number of moves played, states[index] is shown
+	turnStarts []int // index of the first move of each turn
+	autoplay   bool
+	speed      int // index into replaySpeeds
+	ticks      int
+}
+
+// startRecording begins a new recording for a game made with rules.NewGame(width, height, seats)
+func startRecording(g *Game, width int, height int, seats []int) {
+	now := time.Now()
+	record, err := notation.NewRecord(width, height, seats)
+	if err != nil {
+		log.Printf("not recording the game: %v", err)
+		stopRecording(g)
+		return
+	}
+	g.record = record
+	g.record.Tags["Date"] = now.Format("2006-01-02 15:04")
+	g.recordName = replayDirectory + "/" + now.Format("20060102-150405") + ".txt"
+	broadcastChanged(g)
+}
+
+// resumeRecording carries on the recording of a loaded game, into a new replay so the original is kept
+func resumeRecording(g *Game, record notation.Record) {
+	g.record = record
+	g.recordName = replayDirectory + "/" + time.Now().Format("20060102-150405") + ".txt"
+	broadcastChanged(g)
+}
+
+// stopRecording is used for games that can not be replayed from the start, such as old saves
+func stopRecording(g *Game) {
+	g.record = notation.Record{}
+	g.recordName = ""
+	broadcastChanged(g)
+}
+
+// recordMove adds a committed move to the recording, writing it out at the end of each turn
+func recordMove(g *Game, m rules.Move, result rules.Result) {
+	if g.recordName == "" {
+		return
+	}
+	g.record.Moves = append(g.record.Moves, m)
+	if result.TurnEnded || g.state.GameOver {
+		writeRecording(g)
+	}
+}
+
+func writeRecording(g *Game) {
+	if g.recordName == "" {
+		return
+	}
+	data, err := g.record.MarshalText()
+	if err != nil {
+		log.Printf("could not write the recording: %v", err)
+		return
+	}
+	if err := writeStorage(g.recordName, data); err != nil {
+		log.Printf("could not write the recording: %v", err)
+	}
+}
+
+// openReplayPicker lists the most recent recordings, newest first
+func openReplayPicker(g *Game) {
+	names, err := listStorage(replayDirectory)
+	if err != nil {
+		log.Printf("could not list the replays: %v", err)
+	}
+
+	g.uiReplayNames = nil
+	for i := len(names) - 1; i >= 0 && len(g.uiReplayNames) < replayListLength; i-- {
+		g.uiReplayNames = append(g.uiReplayNames, names[i])
+	}
+	g.uiReplaySelected = 0
+	g.uiReplayMessage = ""
+	if len(g.uiReplayNames) == 0 {
+		g.uiReplayMessage = "No replays yet, they are recorded as you play"
+	}
+	g.gameState = 7
+}
+
+// replayLabels describes each recording in the replay picker by when it was played
+func replayLabels(g *Game) []string {
+	labels := make([]string, len(g.uiReplayNames))
+	for i, name := range g.uiReplayNames {
+		labels[i] = name
+		played, err := time.ParseInLocation("20060102-150405", strings.TrimSuffix(name, ".txt"), time.Local)
+		if err == nil {
+			labels[i] = played.Format("02 Jan 2006 15:04")
+		}
+	}
+	return labels
+}
+
+// openReplay loads the recording and shows its first position
+func openReplay(g *Game, name string) error {
+	data, err := readStorage(replayDirectory + "/" + name)
+	if err != nil {
+		return err
+	}
+	record, err := notation.ParseRecord(string(data))
+	if err != nil {
+		return err
+	}
+	states, err := record.States()
+	if err != nil {
+		return err
+	}
+
+	viewer := replayViewer{record: record, states: states, speed: 1, turnStarts: []int{0}}
+	for i := 1; i < len(states); i++ {
+		// the final position of a finished game does not start another turn
+		if states[i].TurnsPlayed != states[i-1].TurnsPlayed && !(i == len(states)-1 && states[i].GameOver) {
+			viewer.turnStarts = append(viewer.turnStarts, i)
+		}
+	}
+	g.replay = viewer
+	g.gameState = 8
+	return nil
+}
+
+// tickReplay is called every frame while the replay is shown, to step the autoplay
+func tickReplay(g *Game) {
+	if !g.replay.autoplay {
+		return
+	}
+	g.replay.ticks++
+	if g.replay.ticks < ebiten.TPS()/replaySpeeds[g.replay.speed] {
+		return
+	}
+	g.replay.ticks = 0
+	if !stepReplay(g, 1) {
+		// stop at the end of the game
+		g.replay.autoplay = false
+	}
+}
+
+// stepReplay moves by a number of moves, and reports if it moved at all
+func stepReplay(g *Game, moves int) bool {
+	return seekReplay(g, g.replay.index+moves)
+}
+
+// seekReplay shows the position after the given number of moves
+func seekReplay(g *Game, index int) bool {
+	index = max(0, min(index, len(g.replay.states)-1))
+	moved := index != g.replay.index
+	g.replay.index = index
+	return moved
+}
+
+// stepReplayTurn jumps to the start of the next or previous turn
+func stepReplayTurn(g *Game, direction int) {
+	current := 0
+	for i, start := range g.replay.turnStarts {
+		if start <= g.replay.index {
+			current = i
+		}
+	}
+
+	if direction < 0 && g.replay.index > g.replay.turnStarts[current] {
+		// go back to the start of this turn first
+		seekReplay(g, g.replay.turnStarts[current])
+		return
+	}
+	next := current + direction
+	if next < 0 {
+		seekReplay(g, 0)
+	} else if next >= len(g.replay.turnStarts) {
+		seekReplay(g, len(g.replay.states)-1)
+	} else {
+		seekReplay(g, g.replay.turnStarts[next])
+	}
+}
+
+// replayTurn is the 1 based turn the shown position is in
+func replayTurn(g *Game) int {
+	turn := 0
+	for i, start := range g.replay.turnStarts {
+		if start <= g.replay.index {
+			turn = i + 1
+		}
+	}
+	return max(turn, 1)
+}
+
+// drawReplay draws the position the replay is at, with the move that led to it highlighted
+func drawReplay(g *Game, screen *ebiten.Image, textSource *text.GoTextFaceSource) {
+	state := g.replay.states[g.replay.index]
+	tileSize := tileSizeFor(g, state.Board)
+
+	drawBoardTiles(screen, state.Board, tileSize)
+
+	if g.replay.index > 0 {
+		last := g.replay.record.Moves[g.replay.index-1]
+		if last.Kind != rules.KindEndTurn {
+			for _, p := range []rules.Position{last.From, last.To} {
+				lastBox := ebiten.NewImage(tileSize, tileSize)
+				lastBox.Fill(color.RGBA{0xff, 0xaa, 0x00, 0xff})
+				op := &ebiten.DrawImageOptions{}
+				op.GeoM.Translate(float64(p.X*tileSize), float64(p.Y*tileSize))
+				screen.DrawImage(lastBox, op)
+			}
+		}
+	}
+
+	drawPieces(screen, state, tileSize, textSource)
+
+	status := fmt.Sprintf("Turn %v of %v, move %v of %v, %v to play", replayTurn(g), len(g.replay.turnStarts),
+		g.replay.index, len(g.replay.states)-1, state.CurrentPlayer().Name)
+	if state.GameOver {
+		status = fmt.Sprintf("Turn %v of %v, move %v of %v, result: %v", replayTurn(g), len(g.replay.turnStarts),
+			g.replay.index, len(g.replay.states)-1, notation.Result(state))
+	}
+	autoplay := "paused"
+	if g.replay.autoplay {
+		autoplay = "playing"
+	}
+	lines := []string{
+		status,
+		fmt.Sprintf("%v at %v moves a second, 'space' play/pause 'enter' speed", autoplay, replaySpeeds[g.replay.speed]),
+		"'left/right' step, 'up/down' turn, 'home/end' jump, 'esc' back",
+	}
+
+	uiStatusY := (state.Board.Height + 1) * tileSize
+	for i, line := range lines {
+		op := &text.DrawOptions{}
+		op.GeoM.Translate(20, float64(uiStatusY+4+i*22))
+		op.ColorScale.ScaleWithColor(color.White)
+		text.Draw(screen, line, &text.GoTextFace{
+			Source: textSource,
+			Size:   16,
+		}, op)
+	}
+}
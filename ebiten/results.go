@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"sixDivides/rules"
+)
+
+// showResultsIfOver moves to the results screen once the rules have ended the game
+func showResultsIfOver(g *Game) {
+	if !g.state.GameOver {
+		return
+	}
+
+	if g.state.Winner == rules.NoWinner {
+		log.Printf("Game over! Nobody is able to move, the game is a draw")
+	} else {
+		log.Printf("Game over! Player %s has won the game", g.state.Players[g.state.Winner].Name)
+	}
+	g.SelectedTile = rules.NoPosition
+	g.gameState = 6
+}
+
+// resultsLabels describes how the game ended, and how each player did
+func resultsLabels(g *Game) []string {
+	labels := []string{}
+	if g.state.Winner == rules.NoWinner {
+		labels = append(labels, "Draw, nobody can move")
+	} else {
+		labels = append(labels, fmt.Sprintf("Winner: %v", g.state.Players[g.state.Winner].Name))
+	}
+	labels = append(labels, fmt.Sprintf("Turns played: %v", g.state.TurnsPlayed))
+
+	for _, player := range g.state.Players {
+		status := fmt.Sprintf("%v pieces left", len(player.Pieces))
+		if player.Eliminated {
+			status = "eliminated"
+		}
+		labels = append(labels, fmt.Sprintf("%v: %v captured, %v", player.Name, player.Captured, status))
+	}
+	return labels
+}
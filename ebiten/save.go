@@ -0,0 +1,262 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"time"
+
+	"sixDivides/notation"
+	"sixDivides/rules"
+)
+
+// saveVersion is bumped whenever the layout of saveFile changes, older
+// versions can then be upgraded when they are loaded
+//
+//	1 - first version
+//	2 - added the winner, turns played and each players eliminated and captured counts
+//	3 - added the record of the game so far, so it can still be replayed after loading
+//	4 - added the bot playing each player, older saves are all humans
+const saveVersion = 4
+
+// saveSlots is the number of save slots shown in the slot picker
+const saveSlots = 5
+
+type saveFile struct {
+	Version     int          `json:"version"`
+	SavedAt     time.Time    `json:"savedAt"`
+	Width       int          `json:"width"`
+	Height      int          `json:"height"`
+	Turn        int          `json:"turn"`
+	GameOver    bool         `json:"gameOver"`
+	Winner      int          `json:"winner"`
+	TurnsPlayed int          `json:"turnsPlayed"`
+	Players     []savePlayer `json:"players"`
+	// Record is the notation record of every move from the start, empty when it is not known
+	Record string `json:"record,omitempty"`
+}
+
+type savePlayer struct {
+	Name             string         `json:"name"`
+	Color            color.RGBA     `json:"color"`
+	PlayerIndex      int            `json:"playerIndex"`
+	StartingPosition rules.Position `json:"startingPosition"`
+	Actions          int            `json:"actions"`
+	Eliminated       bool           `json:"eliminated"`
+	Captured         int            `json:"captured"`
+	Pieces           []savePiece    `json:"pieces"`
+	// Bot is the kind of bot playing, empty for a human
+	Bot string `json:"bot,omitempty"`
+}
+
+type savePiece struct {
+	Value    int            `json:"value"`
+	Position rules.Position `json:"position"`
+}
+
+func saveSlotName(slot int) string {
+	return fmt.Sprintf("saves/slot%d.json", slot+1)
+}
+
+// encodeSave turns the game state into the json save file format
+func encodeSave(s rules.State, record notation.Record, bots []string, savedAt time.Time) ([]byte, error) {
+	save := saveFile{
+		Version:     saveVersion,
+		SavedAt:     savedAt,
+		Width:       s.Board.Width,
+		Height:      s.Board.Height,
+		Turn:        s.Turn,
+		GameOver:    s.GameOver,
+		Winner:      s.Winner,
+		TurnsPlayed: s.TurnsPlayed,
+		Players:     make([]savePlayer, len(s.Players)),
+	}
+
+	if record.Seats != nil {
+		text, err := record.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		save.Record = string(text)
+	}
+
+	for i, player := range s.Players {
+		save.Players[i] = savePlayer{
+			Name:             player.Name,
+			Color:            color.RGBAModel.Convert(player.Color).(color.RGBA),
+			PlayerIndex:      player.PlayerIndex,
+			StartingPosition: player.StartingPosition,
+			Actions:          player.Actions,
+			Eliminated:       player.Eliminated,
+			Captured:         player.Captured,
+			Pieces:           make([]savePiece, len(player.Pieces)),
+		}
+		if i < len(bots) {
+			save.Players[i].Bot = bots[i]
+		}
+		for p, piece := range player.Pieces {
+			save.Players[i].Pieces[p] = savePiece{Value: piece.Value, Position: piece.Position}
+		}
+	}
+
+	return json.MarshalIndent(save, "", "\t")
+}
+
+// decodeSave reads a save file, and checks it describes a game that can be played
+func decodeSave(data []byte) (saveFile, rules.State, error) {
+	var save saveFile
+	if err := json.Unmarshal(data, &save); err != nil {
+		return save, rules.State{}, err
+	}
+	if save.Version < 1 || save.Version > saveVersion {
+		return save, rules.State{}, fmt.Errorf("unsupported save version %d", save.Version)
+	}
+	if save.Version == 1 {
+		// version 1 did not track a winner or eliminations, the game carries on from here
+		save.Winner = rules.NoWinner
+		for i := range save.Players {
+			save.Players[i].Eliminated = len(save.Players[i].Pieces) == 0
+		}
+	}
+	if len(save.Players) == 0 || save.Turn < 0 || save.Turn >= len(save.Players) {
+		return save, rules.State{}, errors.New("save has no player for the current turn")
+	}
+	if err := rules.CheckBoardSize(save.Width, save.Height); err != nil {
+		return save, rules.State{}, err
+	}
+	if save.Winner != rules.NoWinner && (save.Winner < 0 || save.Winner >= len(save.Players) || !save.GameOver) {
+		return save, rules.State{}, fmt.Errorf("save has winner %d", save.Winner)
+	}
+
+	s := rules.State{
+		Board:       rules.CreateBoard(save.Width, save.Height),
+		Players:     make([]rules.Player, len(save.Players)),
+		Turn:        save.Turn,
+		GameOver:    save.GameOver,
+		Winner:      save.Winner,
+		TurnsPlayed: save.TurnsPlayed,
+	}
+	taken := map[rules.Position]bool{}
+	for i, player := range save.Players {
+		// the rules find a pieces player by its index, so they have to match
+		if player.PlayerIndex != i {
+			return save, rules.State{}, fmt.Errorf("%v has player index %d, not %d", player.Name, player.PlayerIndex, i)
+		}
+		// a player is out as soon as their last piece goes, and never comes back
+		if player.Eliminated != (len(player.Pieces) == 0) {
+			return save, rules.State{}, fmt.Errorf("%v has %d pieces, but eliminated is %v", player.Name, len(player.Pieces), player.Eliminated)
+		}
+		s.Players[i] = rules.Player{
+			Color:            player.Color,
+			Name:             player.Name,
+			Actions:          player.Actions,
+			PlayerIndex:      player.PlayerIndex,
+			StartingPosition: player.StartingPosition,
+			Eliminated:       player.Eliminated,
+			Captured:         player.Captured,
+			Pieces:           make([]rules.Piece, len(player.Pieces)),
+		}
+		for p, piece := range player.Pieces {
+			if !s.Board.InBounds(piece.Position) {
+				return save, rules.State{}, fmt.Errorf("piece of %v at %d, %d is off the board", player.Name, piece.Position.X, piece.Position.Y)
+			}
+			if taken[piece.Position] {
+				return save, rules.State{}, fmt.Errorf("more than one piece at %d, %d", piece.Position.X, piece.Position.Y)
+			}
+			taken[piece.Position] = true
+			if piece.Value < 1 || piece.Value > 6 {
+				return save, rules.State{}, fmt.Errorf("piece of %v at %d, %d has value %d", player.Name, piece.Position.X, piece.Position.Y, piece.Value)
+			}
+			s.Players[i].Pieces[p] = rules.Piece{Color: player.Color, Value: piece.Value, PlayerIndex: player.PlayerIndex, Position: piece.Position}
+		}
+	}
+	if err := checkGameOver(s); err != nil {
+		return save, rules.State{}, err
+	}
+	s.SyncBoard()
+
+	return save, s, nil
+}
+
+// checkGameOver makes sure a finished game ended the way the rules end one,
+// won by the last player left, or with nobody left able to act
+func checkGameOver(s rules.State) error {
+	if !s.GameOver {
+		return nil
+	}
+	remaining := s.Remaining()
+	if s.Winner != rules.NoWinner {
+		if len(remaining) != 1 || remaining[0] != s.Winner {
+			return fmt.Errorf("save is won by %v, but %d players are left", s.Players[s.Winner].Name, len(remaining))
+		}
+		return nil
+	}
+	for _, i := range remaining {
+		if s.Players[i].Actions > 0 || rules.ActionsFor(s.Players[i]) > 0 {
+			return fmt.Errorf("save is a draw, but %v can still act", s.Players[i].Name)
+		}
+	}
+	return nil
+}
+
+func saveGame(slot int, s rules.State, record notation.Record, bots []string) error {
+	data, err := encodeSave(s, record, bots, time.Now())
+	if err != nil {
+		return err
+	}
+	return writeStorage(saveSlotName(slot), data)
+}
+
+// loadGame reads the game in the slot, the record of its moves when the save
+// has one, and the kind of bot playing each player
+func loadGame(slot int) (rules.State, notation.Record, []string, error) {
+	data, err := readStorage(saveSlotName(slot))
+	if err != nil {
+		return rules.State{}, notation.Record{}, nil, err
+	}
+	save, s, err := decodeSave(data)
+	if err != nil {
+		return s, notation.Record{}, nil, err
+	}
+
+	bots := make([]string, len(save.Players))
+	for i, player := range save.Players {
+		bots[i] = player.Bot
+	}
+
+	if save.Record == "" {
+		return s, notation.Record{}, bots, nil
+	}
+	record, err := notation.ParseRecord(save.Record)
+	if err != nil {
+		// the game can still be played, it just can not be replayed from the start
+		return s, notation.Record{}, bots, nil
+	}
+	return s, record, bots, nil
+}
+
+// saveSlotLabels describes what is in each save slot for the slot picker
+func saveSlotLabels() []string {
+	labels := make([]string, saveSlots)
+	for slot := range labels {
+		data, err := readStorage(saveSlotName(slot))
+		if errors.Is(err, fs.ErrNotExist) {
+			labels[slot] = fmt.Sprintf("%v: Empty", slot+1)
+			continue
+		}
+		if err != nil {
+			labels[slot] = fmt.Sprintf("%v: Unreadable", slot+1)
+			continue
+		}
+
+		save, s, err := decodeSave(data)
+		if err != nil {
+			labels[slot] = fmt.Sprintf("%v: Unreadable", slot+1)
+			continue
+		}
+		labels[slot] = fmt.Sprintf("%v: %v, %v to play", slot+1, save.SavedAt.Local().Format("02 Jan 15:04"), s.CurrentPlayer().Name)
+	}
+	return labels
+}
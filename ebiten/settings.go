@@ -0,0 +1,270 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"sixDivides/rules"
+)
+
+const settingsVersion = 1
+
+const settingsFileName = "settings.json"
+
+// Settings are the user preferences kept between runs of the game
+type Settings struct {
+	Version      int     `json:"version"`
+	ScreenWidth  int     `json:"screenWidth"`
+	ScreenHeight int     `json:"screenHeight"`
+	UIScale      float64 `json:"uiScale"`
+	BoardWidth   int     `json:"boardWidth"`
+	BoardHeight  int     `json:"boardHeight"`
+	Seats        []int   `json:"seats"`
+	KeyLayout    string  `json:"keyLayout"`
+	// UnrestrictedUndo lets undo go back past the current players turn, for practice games
+	UnrestrictedUndo bool `json:"unrestrictedUndo"`
+	// ServerURL is the WebSocket address of the server used by the Online button
+	ServerURL string `json:"serverURL"`
+	// PlayerName is shown to the others in an online room, the server picks one when it is empty
+	PlayerName string `json:"playerName"`
+	// Broadcast publishes local games to the server for others to watch, BroadcastDelay seconds behind
+	Broadcast      bool `json:"broadcast"`
+	BroadcastDelay int  `json:"broadcastDelay"`
+	// KeyBindings are the keys of the actions that have been changed from the key layout, by action name
+	KeyBindings map[string][]string `json:"keyBindings,omitempty"`
+	// ExternalBots are commands of bot programs, see the bot package, that can be picked for a seat like the built in bots
+	ExternalBots []string `json:"externalBots"`
+}
+
+// the options the settings screen cycles through with the left and right keys
+var (
+	screenSizeOptions = []rules.Position{{X: 640, Y: 720}, {X: 720, Y: 800}, {X: 800, Y: 880}}
+	uiScaleOptions    = []float64{0.75, 1, 1.25, 1.5, 2}
+	// board sizes are the highest tile index, as used by rules.CreateBoard
+	boardSizeOptions = squareBoardSizes()
+	seatOptions      = [][]int{{-1, 2, 1, -1}, {1, 2, 3, -1}, {1, 2, 3, 4}}
+	// see keyLayouts
+	keyLayoutOptions = []string{"Arrows", "WASD", "HJKL"}
+)
+
+// settings screen rows
+const (
+	settingsRowScreenSize = iota
+	settingsRowUIScale
+	settingsRowBoardSize
+	settingsRowSeats
+	settingsRowKeyLayout
+	settingsRowKeyBindings
+	settingsRowUndo
+	settingsRowBroadcast
+	settingsRowBack
+	settingsRowCount
+)
+
+func defaultSettings() Settings {
+	return Settings{
+		Version:      settingsVersion,
+		ScreenWidth:  640,
+		ScreenHeight: 720,
+		UIScale:      1,
+		BoardWidth:   7, // 8 by 8 tiles
+		BoardHeight:  7,
+		Seats:        []int{-1, 2, 1, -1},
+		KeyLayout:    "Arrows",
+		ServerURL:    defaultServerURL(),
+	}
+}
+
+// loadSettings reads the saved settings, falling back to the defaults for anything missing or unreadable
+func loadSettings() Settings {
+	settings := defaultSettings()
+
+	data, err := readStorage(settingsFileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return settings
+	}
+	if err != nil {
+		log.Printf("could not read settings, using defaults: %v", err)
+		return settings
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		log.Printf("could not read settings, using defaults: %v", err)
+		return defaultSettings()
+	}
+
+	// make sure a hand edited file can not break the game
+	defaults := defaultSettings()
+	if settings.ScreenWidth < 320 || settings.ScreenHeight < 360 {
+		settings.ScreenWidth, settings.ScreenHeight = defaults.ScreenWidth, defaults.ScreenHeight
+	}
+	if settings.UIScale <= 0 {
+		settings.UIScale = defaults.UIScale
+	}
+	if rules.CheckBoardSize(settings.BoardWidth, settings.BoardHeight) != nil {
+		settings.BoardWidth, settings.BoardHeight = defaults.BoardWidth, defaults.BoardHeight
+	}
+	if rules.CheckSeats(settings.Seats) != nil {
+		settings.Seats = defaults.Seats
+	}
+	if settings.ServerURL == "" {
+		settings.ServerURL = defaults.ServerURL
+	}
+	if settings.BroadcastDelay < 0 {
+		settings.BroadcastDelay = 0
+	}
+	if _, ok := keyLayouts[settings.KeyLayout]; !ok {
+		settings.KeyLayout = defaults.KeyLayout
+	}
+	settings.Version = settingsVersion
+
+	return settings
+}
+
+func saveSettings(settings Settings) error {
+	data, err := json.MarshalIndent(settings, "", "\t")
+	if err != nil {
+		return err
+	}
+	return writeStorage(settingsFileName, data)
+}
+
+// applySettings updates the window and key bindings to match the settings
+func applySettings(g *Game) {
+	g.screenSize = rules.Position{X: g.settings.ScreenWidth, Y: g.settings.ScreenHeight}
+	g.bindings = bindingsFor(g.settings)
+	ebiten.SetWindowSize(int(float64(g.screenSize.X)*g.settings.UIScale), int(float64(g.screenSize.Y)*g.settings.UIScale))
+	fitTileSize(g)
+
+	// only start a new broadcast when it is turned on or changed, so watchers keep the same one
+	if !g.settings.Broadcast {
+		stopBroadcast(g)
+	} else if g.broadcast == nil || g.broadcast.delay != g.settings.BroadcastDelay {
+		startBroadcast(g)
+	}
+}
+
+// changeSetting moves the setting on the row to the next or previous option
+func changeSetting(g *Game, row int, direction int) {
+	switch row {
+	case settingsRowScreenSize:
+		i := cycleOption(len(screenSizeOptions), indexOfPosition(screenSizeOptions, rules.Position{X: g.settings.ScreenWidth, Y: g.settings.ScreenHeight}), direction)
+		g.settings.ScreenWidth, g.settings.ScreenHeight = screenSizeOptions[i].X, screenSizeOptions[i].Y
+	case settingsRowUIScale:
+		current := 0
+		for i, scale := range uiScaleOptions {
+			if scale == g.settings.UIScale {
+				current = i
+			}
+		}
+		g.settings.UIScale = uiScaleOptions[cycleOption(len(uiScaleOptions), current, direction)]
+	case settingsRowBoardSize:
+		i := cycleOption(len(boardSizeOptions), indexOfPosition(boardSizeOptions, rules.Position{X: g.settings.BoardWidth, Y: g.settings.BoardHeight}), direction)
+		g.settings.BoardWidth, g.settings.BoardHeight = boardSizeOptions[i].X, boardSizeOptions[i].Y
+	case settingsRowSeats:
+		current := 0
+		for i, seats := range seatOptions {
+			if fmt.Sprint(seats) == fmt.Sprint(g.settings.Seats) {
+				current = i
+			}
+		}
+		g.settings.Seats = append([]int(nil), seatOptions[cycleOption(len(seatOptions), current, direction)]...)
+	case settingsRowKeyLayout:
+		current := 0
+		for i, layout := range keyLayoutOptions {
+			if layout == g.settings.KeyLayout {
+				current = i
+			}
+		}
+		g.settings.KeyLayout = keyLayoutOptions[cycleOption(len(keyLayoutOptions), current, direction)]
+	case settingsRowUndo:
+		g.settings.UnrestrictedUndo = !g.settings.UnrestrictedUndo
+	case settingsRowBroadcast:
+		current := 0
+		for i, delay := range broadcastDelayOptions {
+			if g.settings.Broadcast && delay == g.settings.BroadcastDelay {
+				current = i
+			}
+		}
+		delay := broadcastDelayOptions[cycleOption(len(broadcastDelayOptions), current, direction)]
+		g.settings.Broadcast = delay >= 0
+		g.settings.BroadcastDelay = max(delay, 0)
+	}
+
+	// the window can change straight away, the key layout waits until leaving the screen so the
+	// keys being used to change it keep working
+	g.screenSize = rules.Position{X: g.settings.ScreenWidth, Y: g.settings.ScreenHeight}
+	ebiten.SetWindowSize(int(float64(g.screenSize.X)*g.settings.UIScale), int(float64(g.screenSize.Y)*g.settings.UIScale))
+	fitTileSize(g)
+}
+
+// closeSettings applies and stores the settings, then goes back to the pause menue
+func closeSettings(g *Game) {
+	applySettings(g)
+	if err := saveSettings(g.settings); err != nil {
+		log.Printf("could not save settings: %v", err)
+	}
+	// new games start with the chosen players
+	copy(g.uiNewGameSectionPlayer, g.settings.Seats)
+	g.gameState = 1
+}
+
+// settingsLabels describes the current value of each settings row
+func settingsLabels(g *Game) []string {
+	players := 0
+	for _, seat := range g.settings.Seats {
+		if seat != -1 {
+			players++
+		}
+	}
+
+	labels := make([]string, settingsRowCount)
+	labels[settingsRowScreenSize] = fmt.Sprintf("Screen: %vx%v", g.settings.ScreenWidth, g.settings.ScreenHeight)
+	labels[settingsRowUIScale] = fmt.Sprintf("UI scale: %vx", g.settings.UIScale)
+	labels[settingsRowBoardSize] = fmt.Sprintf("New game board: %vx%v", g.settings.BoardWidth+1, g.settings.BoardHeight+1)
+	labels[settingsRowSeats] = fmt.Sprintf("New game players: %v", players)
+	labels[settingsRowKeyLayout] = fmt.Sprintf("Movement keys: %v", g.settings.KeyLayout)
+	labels[settingsRowKeyBindings] = "Key bindings"
+	if len(g.settings.KeyBindings) > 0 {
+		labels[settingsRowKeyBindings] = fmt.Sprintf("Key bindings: %v changed", len(g.settings.KeyBindings))
+	}
+	labels[settingsRowUndo] = "Undo: current turn only"
+	if g.settings.UnrestrictedUndo {
+		labels[settingsRowUndo] = "Undo: any move (practice)"
+	}
+	labels[settingsRowBroadcast] = "Broadcast games: off"
+	if g.settings.Broadcast && g.settings.BroadcastDelay > 0 {
+		labels[settingsRowBroadcast] = fmt.Sprintf("Broadcast games: %vs behind", g.settings.BroadcastDelay)
+	} else if g.settings.Broadcast {
+		labels[settingsRowBroadcast] = "Broadcast games: live"
+	}
+	labels[settingsRowBack] = "Back"
+	return labels
+}
+
+func cycleOption(count int, current int, direction int) int {
+	return (current + direction + count) % count
+}
+
+func indexOfPosition(options []rules.Position, p rules.Position) int {
+	for i, option := range options {
+		if option == p {
+			return i
+		}
+	}
+	return 0
+}
+
+// fitTileSize makes the tiles as large as possible, while leaving room for the status text under the board
+func fitTileSize(g *Game) {
+	g.tileSize = tileSizeFor(g, g.state.Board)
+}
+
+func tileSizeFor(g *Game, board rules.Board) int {
+	columns := board.Width + 1
+	rows := board.Height + 1
+	return min(g.screenSize.X/columns, (g.screenSize.Y-80)/rows)
+}
@@ -0,0 +1,65 @@
+//go:build !js
+
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// storagePath is where a named file is kept in the users config directory
+func storagePath(name string) (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "sixDivides", filepath.FromSlash(name)), nil
+}
+
+// readStorage reads a file previously written with writeStorage
+func readStorage(name string) ([]byte, error) {
+	path, err := storagePath(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// writeStorage writes the data to the named file, making any missing directories
+func writeStorage(name string, data []byte) error {
+	path, err := storagePath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// listStorage returns the names of the files in the directory, sorted by name
+func listStorage(dir string) ([]string, error) {
+	path, err := storagePath(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
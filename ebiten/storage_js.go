@@ -0,0 +1,71 @@
+//go:build js
+
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"sort"
+	"strings"
+	"syscall/js"
+)
+
+// in the browser there is no file system, so the files are kept in localStorage under a prefixed key
+const storageKeyPrefix = "sixDivides/"
+
+func localStorage() (js.Value, error) {
+	storage := js.Global().Get("localStorage")
+	if storage.IsUndefined() || storage.IsNull() {
+		return js.Value{}, errors.New("localStorage is not available")
+	}
+	return storage, nil
+}
+
+// readStorage reads a file previously written with writeStorage
+func readStorage(name string) ([]byte, error) {
+	storage, err := localStorage()
+	if err != nil {
+		return nil, err
+	}
+	item := storage.Call("getItem", storageKeyPrefix+name)
+	if item.IsNull() {
+		return nil, fs.ErrNotExist
+	}
+	return []byte(item.String()), nil
+}
+
+// writeStorage writes the data to the named localStorage key
+func writeStorage(name string, data []byte) (err error) {
+	storage, err := localStorage()
+	if err != nil {
+		return err
+	}
+
+	// setItem throws when the storage quota is used up, which panics on the go side
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("could not write to localStorage")
+		}
+	}()
+	storage.Call("setItem", storageKeyPrefix+name, string(data))
+	return nil
+}
+
+// listStorage returns the names of the files in the directory, sorted by name
+func listStorage(dir string) ([]string, error) {
+	storage, err := localStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := storageKeyPrefix + dir + "/"
+	var names []string
+	for i := 0; i < storage.Get("length").Int(); i++ {
+		key := storage.Call("key", i).String()
+		if name, ok := strings.CutPrefix(key, prefix); ok && !strings.Contains(name, "/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -57,7 +57,7 @@ for /L %%i in (0,1,!length!) do (
 
     echo   Platform: !GOOS!, Architecture: !GOARCH!, Extension: !BIN_EXT!
 
-   go build -o "builds\!GOOS!-!GOARCH!!BIN_EXT!" main.go
+   go build -o "builds\!GOOS!-!GOARCH!!BIN_EXT!" .
 )
 
 echo Finished Building!
@@ -2,7 +2,10 @@ module sixDivides
 
 go 1.21.3
 
-require github.com/hajimehoshi/ebiten/v2 v2.7.2
+require (
+	github.com/coder/websocket v1.8.12
+	github.com/hajimehoshi/ebiten/v2 v2.7.2
+)
 
 require (
 	github.com/ebitengine/gomobile v0.0.0-20240329170434-1771503ff0a8 // indirect
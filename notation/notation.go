@@ -0,0 +1,116 @@
+// Package notation reads and writes sixDivides moves and whole game records
+// as plain text.
+//
+// A tile is written as its column letter followed by its row number, so the
+// top left tile is a1 and the tile at X 2, Y 1 is c2. A move is the tile the
+// piece is on, a symbol for the kind of move, and the tile it acts on:
+//
+//	b2-b3  move onto an empty tile
+//	b2*b3  spawn a 1 from a 6
+//	b2+c2  merge into an own piece, making 6 or less
+//	b2/c2  split into an own piece, making it a 6 and keeping the rest
+//	b2xc3  attack with a 2 or 4
+//	b2!c3  outpost strike, a 6 taking 1 off an enemy piece
+//	b2^c2  reinforce, a 6 adding 1 to an own piece
+//	pass   end the turn early
+//
+// A record is a header of [Name "value"] tags followed by the moves, one line
+// per turn. Anything after a ; is a comment.
+//
+//	[Game "sixDivides"]
+//	[Board "8x8"]
+//	[Seats "-1 2 1 -1"]
+//	[Player1 "g2"]
+//	[Player2 "b7"]
+//	[Result "*"]
+//
+//	1. g2*g3 g2*f2 g3-g4
+//	2. b7*b6 b7*c7 b6-b5
+package notation
+
+import (
+	"fmt"
+	"strconv"
+
+	"sixDivides/rules"
+)
+
+// kindSymbols is the symbol written between the two tiles for each kind of move
+var kindSymbols = map[rules.MoveKind]byte{
+	rules.KindMove:          '-',
+	rules.KindSpawn:         '*',
+	rules.KindMerge:         '+',
+	rules.KindSplit:         '/',
+	rules.KindAttack:        'x',
+	rules.KindOutpostStrike: '!',
+	rules.KindReinforce:     '^',
+}
+
+// PassToken is written for rules.KindEndTurn
+const PassToken = "pass"
+
+// FormatPosition writes the tile as column letter and row number, such as b2
+func FormatPosition(p rules.Position) string {
+	return fmt.Sprintf("%c%d", 'a'+rune(p.X), p.Y+1)
+}
+
+// ParsePosition reads a tile written by FormatPosition
+func ParsePosition(s string) (rules.Position, error) {
+	if len(s) < 2 || s[0] < 'a' || s[0] > 'z' {
+		return rules.NoPosition, fmt.Errorf("invalid tile %q", s)
+	}
+	row, err := strconv.Atoi(s[1:])
+	if err != nil || row < 1 {
+		return rules.NoPosition, fmt.Errorf("invalid tile %q", s)
+	}
+	return rules.Position{X: int(s[0] - 'a'), Y: row - 1}, nil
+}
+
+// FormatMove writes the move in notation, the kind has to be known so moves
+// from rules.LegalMoves or a rules.Result can be written directly
+func FormatMove(m rules.Move) (string, error) {
+	if m.Kind == rules.KindEndTurn {
+		return PassToken, nil
+	}
+	symbol, ok := kindSymbols[m.Kind]
+	if !ok {
+		return "", fmt.Errorf("can not write a move of kind %v", m.Kind)
+	}
+	return FormatPosition(m.From) + string(symbol) + FormatPosition(m.To), nil
+}
+
+// ParseMove reads a move written by FormatMove
+func ParseMove(s string) (rules.Move, error) {
+	if s == PassToken {
+		return rules.EndTurnMove, nil
+	}
+
+	// the symbol is the first character after the letter and digits of the first tile
+	i := 1
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i >= len(s) {
+		return rules.Move{}, fmt.Errorf("invalid move %q", s)
+	}
+
+	kind := rules.KindAny
+	for k, symbol := range kindSymbols {
+		if s[i] == symbol {
+			kind = k
+		}
+	}
+	if kind == rules.KindAny {
+		return rules.Move{}, fmt.Errorf("invalid move %q, unknown symbol %q", s, s[i])
+	}
+
+	from, err := ParsePosition(s[:i])
+	if err != nil {
+		return rules.Move{}, fmt.Errorf("invalid move %q: %w", s, err)
+	}
+	to, err := ParsePosition(s[i+1:])
+	if err != nil {
+		return rules.Move{}, fmt.Errorf("invalid move %q: %w", s, err)
+	}
+	return rules.Move{Kind: kind, From: from, To: to}, nil
+}
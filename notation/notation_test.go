@@ -0,0 +1,54 @@
+package notation
+
+import (
+	"testing"
+
+	"sixDivides/rules"
+)
+
+func TestFormatMove(t *testing.T) {
+	tests := []struct {
+		move rules.Move
+		want string
+	}{
+		{rules.Move{Kind: rules.KindMove, From: rules.Position{X: 1, Y: 1}, To: rules.Position{X: 1, Y: 2}}, "b2-b3"},
+		{rules.Move{Kind: rules.KindSpawn, From: rules.Position{X: 1, Y: 1}, To: rules.Position{X: 1, Y: 2}}, "b2*b3"},
+		{rules.Move{Kind: rules.KindMerge, From: rules.Position{X: 1, Y: 1}, To: rules.Position{X: 2, Y: 1}}, "b2+c2"},
+		{rules.Move{Kind: rules.KindSplit, From: rules.Position{X: 1, Y: 1}, To: rules.Position{X: 2, Y: 1}}, "b2/c2"},
+		{rules.Move{Kind: rules.KindAttack, From: rules.Position{X: 1, Y: 1}, To: rules.Position{X: 1, Y: 0}}, "b2xb1"},
+		{rules.Move{Kind: rules.KindOutpostStrike, From: rules.Position{X: 1, Y: 1}, To: rules.Position{X: 0, Y: 1}}, "b2!a2"},
+		{rules.Move{Kind: rules.KindReinforce, From: rules.Position{X: 14, Y: 15}, To: rules.Position{X: 15, Y: 15}}, "o16^p16"},
+		{rules.EndTurnMove, "pass"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			text, err := FormatMove(tt.move)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if text != tt.want {
+				t.Errorf("FormatMove = %q, want %q", text, tt.want)
+			}
+			m, err := ParseMove(text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if m != tt.move {
+				t.Errorf("ParseMove(%q) = %v, want %v", text, m, tt.move)
+			}
+		})
+	}
+
+	// the kind has to be known to be written
+	if _, err := FormatMove(rules.Move{Kind: rules.KindAny}); err == nil {
+		t.Errorf("FormatMove wrote a move of kind any")
+	}
+}
+
+func TestParseMoveErrors(t *testing.T) {
+	for _, text := range []string{"", "b2", "b2b3", "b2?b3", "b2-", "2b-b3", "b0-b1", "b2-3b", "B2-B3", "passing"} {
+		if m, err := ParseMove(text); err == nil {
+			t.Errorf("ParseMove(%q) = %v, want an error", text, m)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package notation
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sixDivides/rules"
+)
+
+// Record is a whole game, with enough in it to replay the game from the start
+type Record struct {
+	// Width and Height are the highest tile index, as used by rules.CreateBoard
+	Width  int
+	Height int
+	// Seats is the player number in each corner section, as used by rules.CreatePlayers
+	Seats []int
+	Moves []rules.Move
+	// Tags are any extra header tags, such as Date or Event
+	Tags map[string]string
+}
+
+// these tags are worked out from the game, so are not kept in Record.Tags
+const (
+	tagGame   = "Game"
+	tagBoard  = "Board"
+	tagSeats  = "Seats"
+	tagResult = "Result"
+)
+
+// NewRecord starts an empty record for a game created with rules.NewGame(width,
+// height, seats), and fails for a board or seats the rules can not set up
+func NewRecord(width int, height int, seats []int) (Record, error) {
+	r := Record{Width: width, Height: height, Seats: append([]int(nil), seats...), Tags: map[string]string{}}
+	return r, r.check()
+}
+
+// check makes sure the game of the record can be set up, as rules.NewGame
+// takes the board and seats as they are
+func (r Record) check() error {
+	if err := rules.CheckBoardSize(r.Width, r.Height); err != nil {
+		return err
+	}
+	return rules.CheckSeats(r.Seats)
+}
+
+// Start is the state the game starts from, for a record that passes check
+// as the ones from NewRecord and ParseRecord do
+func (r Record) Start() rules.State {
+	return rules.NewGame(r.Width, r.Height, r.Seats)
+}
+
+// Replay plays every move of the record through the rules, and returns the final state
+func (r Record) Replay() (rules.State, error) {
+	return r.replay(nil)
+}
+
+// States returns the state after each move, with the starting state first
+func (r Record) States() ([]rules.State, error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+	states := []rules.State{r.Start()}
+	_, err := r.replay(func(s rules.State, _ rules.Result) {
+		states = append(states, s)
+	})
+	return states, err
+}
+
+// replay applies the moves in order, calling after with the state and result of each one
+func (r Record) replay(after func(rules.State, rules.Result)) (rules.State, error) {
+	if err := r.check(); err != nil {
+		return rules.State{}, err
+	}
+
+	s := r.Start()
+	for i, m := range r.Moves {
+		next, result, err := rules.Apply(s, m)
+		if err != nil {
+			text, _ := FormatMove(m)
+			return s, fmt.Errorf("move %d %v: %w", i+1, text, err)
+		}
+		s = next
+		if after != nil {
+			after(s, result)
+		}
+	}
+	return s, nil
+}
+
+// Result is the name of the winner, draw for a game nobody won, or * while the game is still going
+func Result(s rules.State) string {
+	if !s.GameOver {
+		return "*"
+	}
+	if s.Winner == rules.NoWinner {
+		return "draw"
+	}
+	return s.Players[s.Winner].Name
+}
+
+// MarshalText writes the record, it fails if the moves are not legal
+func (r Record) MarshalText() ([]byte, error) {
+	if err := r.check(); err != nil {
+		return nil, err
+	}
+	start := r.Start()
+
+	// replay to split the moves into turns, and to find the result
+	var turns [][]string
+	var turn []string
+	s := start
+	for i, m := range r.Moves {
+		next, result, err := rules.Apply(s, m)
+		if err != nil {
+			text, _ := FormatMove(m)
+			return nil, fmt.Errorf("move %d %v: %w", i+1, text, err)
+		}
+		if m.Kind == rules.KindAny {
+			m.Kind = result.Kind
+		}
+		text, err := FormatMove(m)
+		if err != nil {
+			return nil, err
+		}
+		turn = append(turn, text)
+		if result.TurnEnded {
+			turns = append(turns, turn)
+			turn = nil
+		}
+		s = next
+	}
+	if len(turn) > 0 {
+		turns = append(turns, turn)
+	}
+
+	var b strings.Builder
+	writeTag(&b, tagGame, "sixDivides")
+	writeTag(&b, tagBoard, fmt.Sprintf("%dx%d", r.Width+1, r.Height+1))
+	seats := make([]string, len(r.Seats))
+	for i, seat := range r.Seats {
+		seats[i] = strconv.Itoa(seat)
+	}
+	writeTag(&b, tagSeats, strings.Join(seats, " "))
+	for _, player := range start.Players {
+		writeTag(&b, player.Name, FormatPosition(player.StartingPosition))
+	}
+	writeTag(&b, tagResult, Result(s))
+
+	names := make([]string, 0, len(r.Tags))
+	for name := range r.Tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeTag(&b, name, r.Tags[name])
+	}
+
+	b.WriteString("\n")
+	for i, moves := range turns {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, strings.Join(moves, " "))
+	}
+	return []byte(b.String()), nil
+}
+
+func writeTag(b *strings.Builder, name string, value string) {
+	fmt.Fprintf(b, "[%s %s]\n", name, strconv.Quote(value))
+}
+
+// UnmarshalText reads a record written by MarshalText
+func (r *Record) UnmarshalText(text []byte) error {
+	parsed, err := ParseRecord(string(text))
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+// ParseRecord reads a record written by MarshalText. The moves are not
+// checked against the rules, use Replay for that.
+func ParseRecord(text string) (Record, error) {
+	r := Record{Tags: map[string]string{}}
+	hasBoard, hasSeats := false, false
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			name, value, err := parseTag(line)
+			if err != nil {
+				return r, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			switch name {
+			case tagBoard:
+				width, height, err := rules.ParseBoardSize(value)
+				if err != nil {
+					return r, fmt.Errorf("line %d: %w", lineNumber, err)
+				}
+				r.Width, r.Height = width, height
+				hasBoard = true
+			case tagSeats:
+				for _, field := range strings.Fields(value) {
+					seat, err := strconv.Atoi(field)
+					if err != nil {
+						return r, fmt.Errorf("line %d: invalid seats %q", lineNumber, value)
+					}
+					r.Seats = append(r.Seats, seat)
+				}
+				if err := rules.CheckSeats(r.Seats); err != nil {
+					return r, fmt.Errorf("line %d: %w", lineNumber, err)
+				}
+				hasSeats = true
+			case tagGame, tagResult:
+				// worked out from the moves
+			default:
+				if strings.HasPrefix(name, "Player") {
+					// starting positions come from the seats
+					continue
+				}
+				r.Tags[name] = value
+			}
+			continue
+		}
+
+		for _, field := range strings.Fields(line) {
+			if strings.HasSuffix(field, ".") {
+				// turn number
+				continue
+			}
+			m, err := ParseMove(field)
+			if err != nil {
+				return r, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			r.Moves = append(r.Moves, m)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return r, err
+	}
+
+	if !hasBoard || !hasSeats {
+		return r, fmt.Errorf("record needs both a %v and %v tag", tagBoard, tagSeats)
+	}
+	return r, nil
+}
+
+// stripComment cuts the line at the first ; that is not inside a quoted tag
+// value, as MarshalText writes tag values with any characters in them
+func stripComment(line string) string {
+	quoted := false
+	for i := 0; i < len(line); i++ {
+		switch {
+		case quoted && line[i] == '\\':
+			// the escaped character can not end the value
+			i++
+		case line[i] == '"':
+			quoted = !quoted
+		case line[i] == ';' && !quoted:
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseTag reads a [Name "value"] header line
+func parseTag(line string) (string, string, error) {
+	if !strings.HasSuffix(line, "]") {
+		return "", "", fmt.Errorf("invalid tag %q", line)
+	}
+	name, quoted, ok := strings.Cut(strings.TrimSpace(line[1:len(line)-1]), " ")
+	if !ok {
+		return "", "", fmt.Errorf("invalid tag %q", line)
+	}
+	value, err := strconv.Unquote(strings.TrimSpace(quoted))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid tag value %q", line)
+	}
+	return name, value, nil
+}
@@ -0,0 +1,160 @@
+package notation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"sixDivides/rules"
+)
+
+// playRecord plays the first legal move, or passes every few moves, until
+// there have been the number of moves
+func playRecord(t *testing.T, r Record, moves int) (Record, rules.State) {
+	t.Helper()
+	s := r.Start()
+	for i := 0; i < moves && !s.GameOver; i++ {
+		m := rules.EndTurnMove
+		if legal := rules.LegalMoves(s, s.Turn); len(legal) > 0 && i%5 != 4 {
+			m = legal[i%len(legal)]
+		}
+		next, _, err := rules.Apply(s, m)
+		if err != nil {
+			t.Fatalf("move %d %v: %v", i+1, m, err)
+		}
+		r.Moves = append(r.Moves, m)
+		s = next
+	}
+	return r, s
+}
+
+func TestRecordRoundTrip(t *testing.T) {
+	tests := []struct {
+		width, height int
+		seats         []int
+		moves         int
+	}{
+		{7, 7, []int{-1, 2, 1, -1}, 0},
+		{7, 7, []int{-1, 2, 1, -1}, 40},
+		{5, 9, []int{1, 2, 3, 4}, 60},
+		{15, 15, []int{-1, -1, 1, -1}, 20},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%vx%v %v %v moves", tt.width+1, tt.height+1, tt.seats, tt.moves), func(t *testing.T) {
+			r, err := NewRecord(tt.width, tt.height, tt.seats)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r.Tags["Date"] = "2024-01-02 15:04"
+			r.Tags["Event"] = `club night; "final" \ round 2`
+			r, want := playRecord(t, r, tt.moves)
+
+			text, err := r.MarshalText()
+			if err != nil {
+				t.Fatal(err)
+			}
+			parsed, err := ParseRecord(string(text))
+			if err != nil {
+				t.Fatalf("ParseRecord: %v\n%s", err, text)
+			}
+			if fmt.Sprint(parsed.Moves) != fmt.Sprint(r.Moves) || fmt.Sprint(parsed.Seats) != fmt.Sprint(r.Seats) || fmt.Sprint(parsed.Tags) != fmt.Sprint(r.Tags) {
+				t.Errorf("ParseRecord = %+v, want %+v", parsed, r)
+			}
+
+			got, err := parsed.Replay()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("Replay = %+v, want %+v", got, want)
+			}
+			states, err := parsed.States()
+			if err != nil || len(states) != len(r.Moves)+1 {
+				t.Errorf("States = %v states, %v, want %v", len(states), err, len(r.Moves)+1)
+			}
+
+			again, err := parsed.MarshalText()
+			if err != nil || string(again) != string(text) {
+				t.Errorf("MarshalText after parsing = %s, %v, want %s", again, err, text)
+			}
+		})
+	}
+}
+
+func TestParseRecordErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		err  error
+	}{
+		{"seat 3 of 1 player", "[Board \"8x8\"]\n[Seats \"3 -1 -1 -1\"]\n", rules.ErrSeats},
+		{"five sections", "[Board \"8x8\"]\n[Seats \"1 2 3 4 5\"]\n", rules.ErrSeats},
+		{"sat twice", "[Board \"8x8\"]\n[Seats \"1 1 -1 -1\"]\n", rules.ErrSeats},
+		{"nobody sat", "[Board \"8x8\"]\n[Seats \"-1 -1 -1 -1\"]\n", rules.ErrSeats},
+		{"no seats", "[Board \"8x8\"]\n[Seats \"\"]\n", rules.ErrSeats},
+		{"board too big", "[Board \"17x8\"]\n[Seats \"-1 2 1 -1\"]\n", rules.ErrBoardSize},
+		{"board too small", "[Board \"5x8\"]\n[Seats \"-1 2 1 -1\"]\n", rules.ErrBoardSize},
+		{"no board tag", "[Seats \"-1 2 1 -1\"]\n", nil},
+		{"no seats tag", "[Board \"8x8\"]\n", nil},
+		{"bad seat", "[Board \"8x8\"]\n[Seats \"1 two -1 -1\"]\n", nil},
+		{"bad move", "[Board \"8x8\"]\n[Seats \"-1 2 1 -1\"]\n\n1. g2?g3\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseRecord(tt.text)
+			if err == nil {
+				t.Fatalf("ParseRecord did not fail")
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("ParseRecord error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+// TestBadSeats makes sure a record with seats the rules can not set up fails,
+// rather than panicking, however it was made
+func TestBadSeats(t *testing.T) {
+	for _, seats := range [][]int{{3, -1, -1, -1}, {1, 2, 3, 4, 5}, {1, 1, -1, -1}, nil} {
+		t.Run(fmt.Sprint(seats), func(t *testing.T) {
+			if _, err := NewRecord(7, 7, seats); !errors.Is(err, rules.ErrSeats) {
+				t.Errorf("NewRecord error = %v, want %v", err, rules.ErrSeats)
+			}
+			r := Record{Width: 7, Height: 7, Seats: seats}
+			if _, err := r.Replay(); !errors.Is(err, rules.ErrSeats) {
+				t.Errorf("Replay error = %v, want %v", err, rules.ErrSeats)
+			}
+			if _, err := r.States(); !errors.Is(err, rules.ErrSeats) {
+				t.Errorf("States error = %v, want %v", err, rules.ErrSeats)
+			}
+			if _, err := r.MarshalText(); !errors.Is(err, rules.ErrSeats) {
+				t.Errorf("MarshalText error = %v, want %v", err, rules.ErrSeats)
+			}
+		})
+	}
+}
+
+func TestReplayIllegalMove(t *testing.T) {
+	r, err := ParseRecord("[Board \"8x8\"]\n[Seats \"-1 2 1 -1\"]\n\n1. g2*g3 a1-a2\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Replay(); err == nil || !strings.HasPrefix(err.Error(), "move 2 a1-a2") {
+		t.Errorf("Replay error = %v, want move 2 to fail", err)
+	}
+}
+
+func TestParseRecordComments(t *testing.T) {
+	text := "; a game\n[Board \"8x8\"] ; the size\n[Seats \"-1 2 1 -1\"]\n[Event \"a;b \\\"c;\\\" d\"];\n\n1. g2*g3 ; spawn\n; 2. b7*b6\n"
+	r, err := ParseRecord(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Tags["Event"] != `a;b "c;" d` {
+		t.Errorf("Event = %q", r.Tags["Event"])
+	}
+	if len(r.Moves) != 1 {
+		t.Errorf("moves = %v, want the 1 not commented out", r.Moves)
+	}
+}
@@ -0,0 +1,191 @@
+// Package online is the protocol between the sixDivides server and the game
+// clients, sent as one json message per WebSocket message.
+//
+// The server runs the rules and is the only one that decides what a move
+// does. A client sends the moves it wants to make, and every client in the
+// room is then sent each move the server accepted, in order. Clients apply
+// those moves with the same rules package, so they all stay in step without
+// the whole state being sent each time. Moves and games are written in the
+// notation package format.
+//
+// Before that, a room is a lobby. The host creates it and shares the join
+// code, everyone who joins picks one of the four corner sections and marks
+// themselves ready, and the host then starts the game.
+//
+// Each player of a started game has a secret token, so a player who loses
+// their connection can join again as the same player. The server waits a
+// while for them to come back, then skips their turns or lets a bot play
+// them until they do.
+package online
+
+// Path is where the server accepts WebSocket connections
+const Path = "/ws"
+
+// MaxRecordLength is the longest record of a game a client can broadcast
+const MaxRecordLength = 256 << 10
+
+// MaxMessageSize is the read limit of the connections at both ends, as the
+// records of long games are over the 32 KB WebSocket default. A record can be
+// up to twice as long once written as a json string, and the rest of a message is small.
+const MaxMessageSize = 2*MaxRecordLength + 64<<10
+
+// BroadcastsPath lists the games being broadcast, as a json array of Broadcast
+const BroadcastsPath = "/broadcasts"
+
+// message types sent by clients
+const (
+	// TypeCreate creates a room for a Width by Height board, with the client as its host
+	TypeCreate = "create"
+	// TypeJoin joins the room with the join code in Room. With the Token of a player
+	// it takes that player back, to carry on after losing the connection.
+	TypeJoin = "join"
+	// TypeSeat sits the client in Section, or stands them up again with NoSection
+	TypeSeat = "seat"
+	// TypeReady tells the host the client is Ready to play, or not
+	TypeReady = "ready"
+	// TypeStart is sent by the host to start the game once the players are ready
+	TypeStart = "start"
+	// TypeMove asks to play Move for the clients player
+	TypeMove = "move"
+	// TypeBroadcast publishes the Record of a game played at the client for others to watch,
+	// sent again every time the game changes. Name and Delay are taken from the first one.
+	TypeBroadcast = "broadcast"
+	// TypeWatch starts watching the broadcast with the code in Room
+	TypeWatch = "watch"
+)
+
+// message types sent by the server
+const (
+	// TypeLobby is the Lobby of a room whose game has not started, sent whenever it changes
+	TypeLobby = "lobby"
+	// TypeJoined is sent when the game starts, or on joining a room that has already started,
+	// with the clients Player, the Token to get that player back and the Record of the game so far
+	TypeJoined = "joined"
+	// TypeMoved is a Move the server accepted from Player, to be applied by every client
+	TypeMoved = "moved"
+	// TypeBroadcasting is the reply to the first TypeBroadcast, with the code to watch it in Room
+	TypeBroadcasting = "broadcasting"
+	// TypeView is the game being watched, sent whenever it changes once the broadcast delay has passed
+	TypeView = "view"
+	// TypeNotice tells everyone in the room something happened to Player, such as losing their connection
+	TypeNotice = "notice"
+	// TypeError is the reason a request from the client was refused
+	TypeError = "error"
+)
+
+// Spectator is the Player of a client that is not playing, because every player was taken
+// or they did not sit in a section before the game started
+const Spectator = -1
+
+// Sections is the number of corner sections a room has. They are in the
+// order rules.NewGame takes its seats, so 0 is the top left corner, 1 the
+// bottom left, 2 the top right and 3 the bottom right.
+const Sections = 4
+
+// NoSection is the Section of a client that is not sat in one
+const NoSection = -1
+
+// CodeLength is the number of digits in a room join code
+const CodeLength = 5
+
+// ClientMessage is a request from a client to the server
+type ClientMessage struct {
+	Type string `json:"type"`
+	// Room is the join code of the room to join
+	Room string `json:"room,omitempty"`
+	// Name is shown to the others in the room, the server picks one when it is empty
+	Name string `json:"name,omitempty"`
+	// Token is from the TypeJoined message of a player the client is taking back
+	Token string `json:"token,omitempty"`
+	// Width and Height are the board of a room to create, as for rules.NewGame
+	Width   int    `json:"width,omitempty"`
+	Height  int    `json:"height,omitempty"`
+	Section int    `json:"section"`
+	Ready   bool   `json:"ready,omitempty"`
+	Move    string `json:"move,omitempty"`
+	// Record is the game being broadcast, in the notation record format
+	Record string `json:"record,omitempty"`
+	// Delay is how many seconds the watchers of a broadcast are kept behind the game
+	Delay int `json:"delay,omitempty"`
+}
+
+// ServerMessage is sent by the server, either in reply to a ClientMessage or to every client in a room
+type ServerMessage struct {
+	Type string `json:"type"`
+	// Room is the join code of the room
+	Room string `json:"room,omitempty"`
+	// Player is the index of the player the message is about, or Spectator
+	Player int `json:"player"`
+	// Record is the whole game so far, in the notation record format
+	Record string `json:"record,omitempty"`
+	// Token is only sent to the client playing Player, keep it secret from the others
+	Token  string `json:"token,omitempty"`
+	Move   string `json:"move,omitempty"`
+	Lobby  *Lobby `json:"lobby,omitempty"`
+	View   *View  `json:"view,omitempty"`
+	Notice string `json:"notice,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Lobby is a room waiting for its game to start, as seen by the client it is sent to
+type Lobby struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// Seats has one entry for each section
+	Seats []Seat `json:"seats"`
+	// You is the section the client is sat in, or NoSection
+	You int `json:"you"`
+	// Host is true for the client that can start the game
+	Host bool `json:"host"`
+	// Watching is the number of clients in the room that are not sat in a section
+	Watching int `json:"watching"`
+}
+
+// Seat is who is sat in a section, the Name is empty when nobody is.
+// The players are numbered in section order when the game starts.
+type Seat struct {
+	Name  string `json:"name,omitempty"`
+	Ready bool   `json:"ready"`
+	Host  bool   `json:"host"`
+}
+
+// Broadcast is a game being broadcast, as listed at BroadcastsPath
+type Broadcast struct {
+	Code     string   `json:"code"`
+	Name     string   `json:"name"`
+	Players  []string `json:"players"`
+	Turn     int      `json:"turn"`
+	Delay    int      `json:"delay"`
+	Watching int      `json:"watching"`
+}
+
+// View is a position of a broadcast game, with everything a page needs to draw it
+// without knowing the rules
+type View struct {
+	// Columns and Rows are the number of tiles across and down the board
+	Columns int          `json:"columns"`
+	Rows    int          `json:"rows"`
+	Players []ViewPlayer `json:"players"`
+	Pieces  []ViewPiece  `json:"pieces"`
+	// Turn is the index of the player to play
+	Turn int `json:"turn"`
+	// Result is * until the game is over, then the winners name or draw
+	Result string `json:"result"`
+	// History is the moves so far, one line of notation for each turn
+	History []string `json:"history"`
+}
+
+type ViewPlayer struct {
+	Name string `json:"name"`
+	// Color is the css colour of the players pieces
+	Color      string `json:"color"`
+	Actions    int    `json:"actions"`
+	Eliminated bool   `json:"eliminated"`
+}
+
+type ViewPiece struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Value  int `json:"value"`
+	Player int `json:"player"`
+}
@@ -0,0 +1,163 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Result tells the caller what happened to the pieces, so a ui can follow along
+type Result struct {
+	// Kind is what the move turned out to be
+	Kind MoveKind
+	// Highlighted is the tile the action finished on
+	Highlighted Position
+	// Selected is where the moving piece is now, or NoPosition if it was removed
+	Selected Position
+	// TurnEnded is set when the move used the last action and the turn passed on
+	TurnEnded bool
+}
+
+var (
+	ErrGameOver             = errors.New("the game is over")
+	ErrOutOfBounds          = errors.New("tile is not on the board")
+	ErrNotAdjacent          = errors.New("pieces can only act on a neighbouring tile")
+	ErrNoPiece              = errors.New("there is no piece on the selected tile")
+	ErrNotYourPiece         = errors.New("piece does not belong to the current player")
+	ErrNoActions            = errors.New("current player has no actions remaining")
+	ErrOutpostFull          = errors.New("can not add more than 6 to a piece")
+	ErrCombineTwoSixes      = errors.New("cannot combine two 6s")
+	ErrCombineOntoOutpost   = errors.New("cannot split onto a piece that is already 6")
+	ErrGathererCannotAttack = errors.New("gatherer (value 1/3/5) cannot attack")
+	ErrInvalidValue         = errors.New("invalid piece value")
+	ErrWrongKind            = errors.New("move is not the kind it claims to be")
+)
+
+// Apply plays the move for the current player and returns the new state.
+// s is never modified, and on error the returned state is s.
+func Apply(s State, m Move) (State, Result, error) {
+	if s.GameOver {
+		return s, Result{}, ErrGameOver
+	}
+	if m.Kind == KindEndTurn {
+		n := EndTurn(s)
+		return n, Result{Kind: KindEndTurn, Highlighted: NoPosition, Selected: NoPosition, TurnEnded: true}, nil
+	}
+
+	kind, err := Classify(s, m.From, m.To)
+	if err != nil {
+		return s, Result{}, err
+	}
+	if m.Kind != KindAny && m.Kind != kind {
+		return s, Result{}, fmt.Errorf("%w: %v is a %v", ErrWrongKind, m.Kind, kind)
+	}
+
+	selectedPiece, _ := s.PieceAt(m.From)
+	if selectedPiece.PlayerIndex != s.Players[s.Turn].PlayerIndex {
+		return s, Result{}, ErrNotYourPiece
+	}
+	if s.Players[s.Turn].Actions <= 0 {
+		return s, Result{}, ErrNoActions
+	}
+
+	n := s.Clone()
+	res := Result{Kind: kind, Highlighted: m.From, Selected: m.From}
+	targetPiece, _ := n.PieceAt(m.To)
+
+	switch kind {
+	case KindSpawn:
+		// create a piece on the new tile of value 1
+		current := &n.Players[n.Turn]
+		newPiece := Piece{Color: current.Color, Value: 1, PlayerIndex: current.PlayerIndex, Position: m.To}
+		current.Pieces = append(current.Pieces, newPiece)
+	case KindMove:
+		n.movePiece(m.From, m.To)
+		res.Highlighted, res.Selected = m.To, m.To
+	case KindReinforce:
+		n.setPieceValue(m.To, targetPiece.Value+1)
+	case KindMerge:
+		// combine the two pieces and remove the selected piece from the board
+		n.setPieceValue(m.To, targetPiece.Value+selectedPiece.Value)
+		n.removePiece(m.From)
+		res.Highlighted, res.Selected = m.To, m.To
+	case KindSplit:
+		// set the target tile to a value of 6, and leave the remaining value on the selected piece
+		n.setPieceValue(m.To, 6)
+		n.setPieceValue(m.From, targetPiece.Value+selectedPiece.Value-6)
+	case KindAttack:
+		if targetPiece.Value == selectedPiece.Value {
+			// remove both pieces from the board
+			n.removePiece(m.To)
+			n.removePiece(m.From)
+			n.Players[n.Turn].Captured++
+			res.Highlighted, res.Selected = m.To, NoPosition
+		} else if targetPiece.Value < selectedPiece.Value {
+			// remove the target piece, and move the players piece onto it reduced by the targets value
+			n.removePiece(m.To)
+			n.Players[n.Turn].Captured++
+			n.movePiece(m.From, m.To)
+			n.setPieceValue(m.To, selectedPiece.Value-targetPiece.Value)
+			res.Highlighted, res.Selected = m.To, m.To
+		} else {
+			// target piece is larger, and will absorb the selected pieces value
+			n.setPieceValue(m.To, targetPiece.Value-selectedPiece.Value)
+			n.removePiece(m.From)
+			res.Highlighted, res.Selected = m.To, NoPosition
+		}
+	case KindOutpostStrike:
+		// the target is reduced by 1, and removed when it was a 1
+		if targetPiece.Value == 1 {
+			n.removePiece(m.To)
+			n.Players[n.Turn].Captured++
+		} else {
+			n.setPieceValue(m.To, targetPiece.Value-1)
+		}
+	}
+
+	checkEliminations(&n)
+	res.TurnEnded = usePlayerAction(&n)
+	n.SyncBoard()
+	return n, res, nil
+}
+
+// findPiece returns the owning player and index of the piece at the position
+func (s *State) findPiece(p Position) (int, int) {
+	for playerId, player := range s.Players {
+		for i, piece := range player.Pieces {
+			if piece.Position == p {
+				return playerId, i
+			}
+		}
+	}
+	return -1, -1
+}
+
+func (s *State) removePiece(p Position) {
+	playerId, i := s.findPiece(p)
+	if i == -1 {
+		return
+	}
+	s.Players[playerId].Pieces = append(s.Players[playerId].Pieces[:i], s.Players[playerId].Pieces[i+1:]...)
+}
+
+func (s *State) setPieceValue(p Position, newValue int) {
+	playerId, i := s.findPiece(p)
+	if i == -1 {
+		return
+	}
+	s.Players[playerId].Pieces[i].Value = newValue
+}
+
+func (s *State) movePiece(from, to Position) {
+	playerId, i := s.findPiece(from)
+	if i == -1 {
+		return
+	}
+	s.Players[playerId].Pieces[i].Position = to
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
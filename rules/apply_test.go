@@ -0,0 +1,135 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"testing"
+)
+
+// testPiece is a piece for testState, owned by the player with the index
+type testPiece struct {
+	player int
+	value  int
+	x, y   int
+}
+
+var testColors = []color.Color{
+	color.RGBA{0x00, 0x00, 0xff, 0xff},
+	color.RGBA{0xff, 0x00, 0x00, 0xff},
+	color.RGBA{0x00, 0xff, 0xff, 0xff},
+	color.RGBA{0xff, 0x00, 0xff, 0xff},
+}
+
+// testState is a game on a 8x8 board with the players and only the pieces
+// given, the first player to play with actions
+func testState(players int, actions int, pieces ...testPiece) State {
+	s := State{Board: CreateBoard(7, 7), Players: make([]Player, players), Winner: NoWinner}
+	for i := range s.Players {
+		s.Players[i] = Player{Color: testColors[i], Name: fmt.Sprintf("Player%v", i+1), PlayerIndex: i}
+	}
+	for _, p := range pieces {
+		player := &s.Players[p.player]
+		player.Pieces = append(player.Pieces, Piece{Color: player.Color, Value: p.value, PlayerIndex: p.player, Position: Position{X: p.x, Y: p.y}})
+	}
+	s.Players[0].Actions = actions
+	s.SyncBoard()
+	return s
+}
+
+func TestApply(t *testing.T) {
+	// every test has a piece for each player out of the way, so nobody is eliminated
+	spare := []testPiece{{0, 2, 7, 0}, {1, 2, 7, 7}}
+	tests := []struct {
+		name    string
+		pieces  []testPiece
+		actions int
+		move    Move
+		err     error
+		// wantFrom and wantTo are the pieces left on the tiles, a value of 0 for an empty tile
+		wantFrom, wantTo testPiece
+		wantCaptured     int
+	}{
+		{"move", []testPiece{{0, 1, 1, 1}}, 3, Move{KindMove, Position{1, 1}, Position{1, 2}}, nil, testPiece{}, testPiece{0, 1, 1, 2}, 0},
+		{"spawn", []testPiece{{0, 6, 1, 1}}, 3, Move{KindSpawn, Position{1, 1}, Position{2, 1}}, nil, testPiece{0, 6, 1, 1}, testPiece{0, 1, 2, 1}, 0},
+		{"any kind", []testPiece{{0, 6, 1, 1}}, 3, Move{KindAny, Position{1, 1}, Position{2, 1}}, nil, testPiece{0, 6, 1, 1}, testPiece{0, 1, 2, 1}, 0},
+		{"merge", []testPiece{{0, 1, 1, 1}, {0, 2, 1, 2}}, 3, Move{KindMerge, Position{1, 1}, Position{1, 2}}, nil, testPiece{}, testPiece{0, 3, 1, 2}, 0},
+		{"split", []testPiece{{0, 4, 1, 1}, {0, 5, 1, 2}}, 3, Move{KindSplit, Position{1, 1}, Position{1, 2}}, nil, testPiece{0, 3, 1, 1}, testPiece{0, 6, 1, 2}, 0},
+		{"reinforce", []testPiece{{0, 6, 1, 1}, {0, 3, 1, 2}}, 3, Move{KindReinforce, Position{1, 1}, Position{1, 2}}, nil, testPiece{0, 6, 1, 1}, testPiece{0, 4, 1, 2}, 0},
+		{"attack equal", []testPiece{{0, 2, 1, 1}, {1, 2, 1, 2}}, 3, Move{KindAttack, Position{1, 1}, Position{1, 2}}, nil, testPiece{}, testPiece{}, 1},
+		{"attack smaller", []testPiece{{0, 4, 1, 1}, {1, 1, 1, 2}}, 3, Move{KindAttack, Position{1, 1}, Position{1, 2}}, nil, testPiece{}, testPiece{0, 3, 1, 2}, 1},
+		{"attack larger", []testPiece{{0, 2, 1, 1}, {1, 5, 1, 2}}, 3, Move{KindAttack, Position{1, 1}, Position{1, 2}}, nil, testPiece{}, testPiece{1, 3, 1, 2}, 0},
+		{"outpost strike", []testPiece{{0, 6, 1, 1}, {1, 3, 1, 2}}, 3, Move{KindOutpostStrike, Position{1, 1}, Position{1, 2}}, nil, testPiece{0, 6, 1, 1}, testPiece{1, 2, 1, 2}, 0},
+		{"outpost strike a 1", []testPiece{{0, 6, 1, 1}, {1, 1, 1, 2}}, 3, Move{KindOutpostStrike, Position{1, 1}, Position{1, 2}}, nil, testPiece{0, 6, 1, 1}, testPiece{}, 1},
+		{"not your piece", []testPiece{{1, 1, 1, 1}}, 3, Move{KindMove, Position{1, 1}, Position{1, 2}}, ErrNotYourPiece, testPiece{1, 1, 1, 1}, testPiece{}, 0},
+		{"no actions", []testPiece{{0, 1, 1, 1}}, 0, Move{KindMove, Position{1, 1}, Position{1, 2}}, ErrNoActions, testPiece{0, 1, 1, 1}, testPiece{}, 0},
+		{"wrong kind", []testPiece{{0, 1, 1, 1}}, 3, Move{KindAttack, Position{1, 1}, Position{1, 2}}, ErrWrongKind, testPiece{0, 1, 1, 1}, testPiece{}, 0},
+		{"gatherer attack", []testPiece{{0, 1, 1, 1}, {1, 1, 1, 2}}, 3, Move{KindAny, Position{1, 1}, Position{1, 2}}, ErrGathererCannotAttack, testPiece{0, 1, 1, 1}, testPiece{1, 1, 1, 2}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testState(2, tt.actions, append(tt.pieces, spare...)...)
+			before := s.Clone()
+			n, result, err := Apply(s, tt.move)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Apply error = %v, want %v", err, tt.err)
+			}
+			if kind, _ := Classify(before, tt.move.From, tt.move.To); err == nil && result.Kind != kind {
+				t.Errorf("result kind = %v, want %v", result.Kind, kind)
+			}
+			checkPiece(t, n, tt.move.From, tt.wantFrom)
+			checkPiece(t, n, tt.move.To, tt.wantTo)
+			if n.Players[0].Captured != tt.wantCaptured {
+				t.Errorf("captured = %v, want %v", n.Players[0].Captured, tt.wantCaptured)
+			}
+			// s is never modified
+			checkPiece(t, s, tt.move.From, pieceOn(before, tt.move.From))
+			checkPiece(t, s, tt.move.To, pieceOn(before, tt.move.To))
+		})
+	}
+}
+
+// pieceOn is the piece on the tile as a testPiece, with a value of 0 when it is empty
+func pieceOn(s State, p Position) testPiece {
+	piece, ok := s.PieceAt(p)
+	if !ok {
+		return testPiece{}
+	}
+	return testPiece{piece.PlayerIndex, piece.Value, p.X, p.Y}
+}
+
+// checkPiece fails the test when the tile does not have the players piece of the value
+func checkPiece(t *testing.T, s State, p Position, want testPiece) {
+	t.Helper()
+	got := pieceOn(s, p)
+	if got.value != want.value || (want.value != 0 && got.player != want.player) {
+		t.Errorf("piece on %v = player %v value %v, want player %v value %v", p, got.player, got.value, want.player, want.value)
+	}
+}
+
+func TestApplyEndsTurn(t *testing.T) {
+	tests := []struct {
+		name      string
+		actions   int
+		wantEnded bool
+		wantTurn  int
+	}{
+		{"actions left", 2, false, 0},
+		{"last action", 1, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testState(2, tt.actions, testPiece{0, 1, 1, 1}, testPiece{1, 6, 6, 6})
+			n, result, err := Apply(s, Move{KindMove, Position{1, 1}, Position{1, 2}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.TurnEnded != tt.wantEnded || n.Turn != tt.wantTurn {
+				t.Errorf("turn ended = %v on turn %v, want %v on turn %v", result.TurnEnded, n.Turn, tt.wantEnded, tt.wantTurn)
+			}
+			if tt.wantEnded && n.Players[1].Actions != 3 {
+				t.Errorf("next player has %v actions, want the 3 of their 6", n.Players[1].Actions)
+			}
+		})
+	}
+}
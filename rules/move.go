@@ -0,0 +1,151 @@
+package rules
+
+import "fmt"
+
+// MoveKind is what a move does to the pieces involved
+type MoveKind int
+
+const (
+	// KindAny lets Apply work out the kind from the board
+	KindAny MoveKind = iota
+	// KindSpawn is a 6 creating a new 1 on an empty tile
+	KindSpawn
+	// KindMove is a unit moving onto an empty tile
+	KindMove
+	// KindMerge is a unit combining into an own piece making 6 or less
+	KindMerge
+	// KindSplit is a unit combining into an own piece making 7 to 11, the target becomes 6 and the rest stays behind
+	KindSplit
+	// KindAttack is a soldier (2 or 4) attacking an enemy piece
+	KindAttack
+	// KindOutpostStrike is a 6 reducing a neighbouring enemy piece by 1
+	KindOutpostStrike
+	// KindReinforce is a 6 adding 1 to a neighbouring own piece
+	KindReinforce
+	// KindEndTurn ends the current players turn early, keeping their remaining actions.
+	// From and To are not used, and it is never listed by LegalMoves.
+	KindEndTurn
+)
+
+var moveKindNames = []string{"any", "spawn", "move", "merge", "split", "attack", "outpost-strike", "reinforce", "end-turn"}
+
+func (k MoveKind) String() string {
+	if k < 0 || int(k) >= len(moveKindNames) {
+		return fmt.Sprintf("MoveKind(%d)", int(k))
+	}
+	return moveKindNames[k]
+}
+
+// Move is a single action by the piece on From onto the neighbouring tile To
+type Move struct {
+	Kind MoveKind
+	From Position
+	To   Position
+}
+
+// EndTurnMove is the move for ending the turn early
+var EndTurnMove = Move{Kind: KindEndTurn, From: NoPosition, To: NoPosition}
+
+func (m Move) String() string {
+	if m.Kind == KindEndTurn {
+		return m.Kind.String()
+	}
+	return fmt.Sprintf("%v %d,%d -> %d,%d", m.Kind, m.From.X, m.From.Y, m.To.X, m.To.Y)
+}
+
+// Directions are the offsets to the four neighbouring tiles a piece can act on
+var Directions = []Position{{X: 0, Y: -1}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: -1, Y: 0}}
+
+// Classify works out what the piece on from would do to the tile to, or why it can't.
+// It does not check whose turn it is, so it can also be used to look at other players threats.
+func Classify(s State, from, to Position) (MoveKind, error) {
+	if !s.Board.InBounds(from) || !s.Board.InBounds(to) {
+		return KindAny, ErrOutOfBounds
+	}
+	if abs(from.X-to.X)+abs(from.Y-to.Y) != 1 {
+		return KindAny, ErrNotAdjacent
+	}
+
+	selectedPiece, ok := s.PieceAt(from)
+	if !ok {
+		return KindAny, ErrNoPiece
+	}
+	targetPiece, occupied := s.PieceAt(to)
+
+	if !occupied {
+		if selectedPiece.Value == 6 {
+			return KindSpawn, nil
+		}
+		return KindMove, nil
+	}
+
+	if targetPiece.PlayerIndex == selectedPiece.PlayerIndex {
+		// target tile is owned by the same player
+		if selectedPiece.Value == 6 {
+			// selected piece is outpost and max value
+			if targetPiece.Value >= 6 {
+				return KindAny, ErrOutpostFull
+			}
+			return KindReinforce, nil
+		}
+
+		combinedValue := targetPiece.Value + selectedPiece.Value
+		switch combinedValue {
+		case 2, 3, 4, 5, 6:
+			return KindMerge, nil
+		case 7, 8, 9, 10, 11:
+			// only combine pieces where the target piece is not max value
+			if targetPiece.Value >= 6 {
+				return KindAny, ErrCombineOntoOutpost
+			}
+			return KindSplit, nil
+		case 12:
+			// invalid move, as both pieces are at the maximum value
+			return KindAny, ErrCombineTwoSixes
+		default:
+			// should not be able to make 0, 1 or more than 12 by combining two pieces
+			return KindAny, ErrInvalidValue
+		}
+	}
+
+	// target tile belongs to another player
+	switch selectedPiece.Value {
+	case 1, 3, 5:
+		// piece is a gatherer, and can not attack other pieces
+		return KindAny, ErrGathererCannotAttack
+	case 2, 4:
+		return KindAttack, nil
+	case 6:
+		return KindOutpostStrike, nil
+	default:
+		return KindAny, ErrInvalidValue
+	}
+}
+
+// LegalMoves lists every move the player can make right now. It is empty when
+// it is not their turn, they have no actions left or the game is over.
+func LegalMoves(s State, player int) []Move {
+	if s.GameOver || player < 0 || player >= len(s.Players) || s.Turn != player || s.Players[player].Actions <= 0 {
+		return nil
+	}
+
+	var moves []Move
+	for _, piece := range s.Players[player].Pieces {
+		moves = append(moves, PieceMoves(s, piece.Position)...)
+	}
+	return moves
+}
+
+// PieceMoves lists the moves the piece on from can make onto its neighbouring tiles
+func PieceMoves(s State, from Position) []Move {
+	var moves []Move
+	for _, d := range Directions {
+		to := Position{X: from.X + d.X, Y: from.Y + d.Y}
+		kind, err := Classify(s, from, to)
+		if err != nil {
+			continue
+		}
+		moves = append(moves, Move{Kind: kind, From: from, To: to})
+	}
+	return moves
+}
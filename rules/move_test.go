@@ -0,0 +1,85 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		pieces   []testPiece
+		from, to Position
+		want     MoveKind
+		err      error
+	}{
+		{"move", []testPiece{{0, 1, 1, 1}}, Position{1, 1}, Position{1, 2}, KindMove, nil},
+		{"spawn", []testPiece{{0, 6, 1, 1}}, Position{1, 1}, Position{2, 1}, KindSpawn, nil},
+		{"merge", []testPiece{{0, 1, 1, 1}, {0, 2, 1, 2}}, Position{1, 1}, Position{1, 2}, KindMerge, nil},
+		{"merge to 6", []testPiece{{0, 3, 1, 1}, {0, 3, 1, 2}}, Position{1, 1}, Position{1, 2}, KindMerge, nil},
+		{"split", []testPiece{{0, 4, 1, 1}, {0, 4, 1, 2}}, Position{1, 1}, Position{1, 2}, KindSplit, nil},
+		{"split onto a 6", []testPiece{{0, 5, 1, 1}, {0, 6, 1, 2}}, Position{1, 1}, Position{1, 2}, KindAny, ErrCombineOntoOutpost},
+		{"reinforce", []testPiece{{0, 6, 1, 1}, {0, 3, 1, 2}}, Position{1, 1}, Position{1, 2}, KindReinforce, nil},
+		{"reinforce a 6", []testPiece{{0, 6, 1, 1}, {0, 6, 1, 2}}, Position{1, 1}, Position{1, 2}, KindAny, ErrOutpostFull},
+		{"attack", []testPiece{{0, 2, 1, 1}, {1, 5, 1, 2}}, Position{1, 1}, Position{1, 2}, KindAttack, nil},
+		{"gatherer attack", []testPiece{{0, 3, 1, 1}, {1, 2, 1, 2}}, Position{1, 1}, Position{1, 2}, KindAny, ErrGathererCannotAttack},
+		{"outpost strike", []testPiece{{0, 6, 1, 1}, {1, 4, 1, 2}}, Position{1, 1}, Position{1, 2}, KindOutpostStrike, nil},
+		{"diagonal", []testPiece{{0, 1, 1, 1}}, Position{1, 1}, Position{2, 2}, KindAny, ErrNotAdjacent},
+		{"two tiles away", []testPiece{{0, 1, 1, 1}}, Position{1, 1}, Position{1, 3}, KindAny, ErrNotAdjacent},
+		{"off the board", []testPiece{{0, 1, 0, 0}}, Position{0, 0}, Position{-1, 0}, KindAny, ErrOutOfBounds},
+		{"no piece", nil, Position{1, 1}, Position{1, 2}, KindAny, ErrNoPiece},
+		{"other players piece", []testPiece{{1, 1, 1, 1}}, Position{1, 1}, Position{1, 2}, KindMove, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testState(2, 3, tt.pieces...)
+			kind, err := Classify(s, tt.from, tt.to)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Classify error = %v, want %v", err, tt.err)
+			}
+			if kind != tt.want {
+				t.Errorf("Classify = %v, want %v", kind, tt.want)
+			}
+		})
+	}
+}
+
+func TestLegalMoves(t *testing.T) {
+	tests := []struct {
+		name     string
+		pieces   []testPiece
+		actions  int
+		player   int
+		gameOver bool
+		want     map[MoveKind]int
+	}{
+		{"6 in the corner", []testPiece{{0, 6, 0, 0}}, 3, 0, false, map[MoveKind]int{KindSpawn: 2}},
+		{"6 in the middle", []testPiece{{0, 6, 3, 3}}, 3, 0, false, map[MoveKind]int{KindSpawn: 4}},
+		// the 3 and the 2 can not combine with the 6, and only move
+		{"around a 6", []testPiece{{0, 6, 3, 3}, {0, 3, 3, 2}, {1, 2, 4, 3}, {0, 2, 2, 3}}, 3, 0, false,
+			map[MoveKind]int{KindSpawn: 1, KindReinforce: 2, KindOutpostStrike: 1, KindMove: 6}},
+		{"merge and split", []testPiece{{0, 1, 1, 1}, {0, 5, 2, 1}, {0, 4, 3, 1}}, 3, 0, false,
+			map[MoveKind]int{KindMerge: 2, KindSplit: 2, KindMove: 8}},
+		{"not their turn", []testPiece{{0, 6, 3, 3}, {1, 6, 5, 5}}, 3, 1, false, nil},
+		{"no actions", []testPiece{{0, 6, 3, 3}}, 0, 0, false, nil},
+		{"game over", []testPiece{{0, 6, 3, 3}}, 3, 0, true, nil},
+		{"no such player", []testPiece{{0, 6, 3, 3}}, 3, 2, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testState(2, tt.actions, tt.pieces...)
+			s.GameOver = tt.gameOver
+			got := map[MoveKind]int{}
+			for _, m := range LegalMoves(s, tt.player) {
+				if _, _, err := Apply(s, m); err != nil {
+					t.Errorf("%v is not legal: %v", m, err)
+				}
+				got[m.Kind]++
+			}
+			if fmt.Sprint(got) != fmt.Sprint(map[MoveKind]int(tt.want)) && !(len(got) == 0 && len(tt.want) == 0) {
+				t.Errorf("LegalMoves kinds = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
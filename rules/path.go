@@ -0,0 +1,87 @@
+package rules
+
+import "errors"
+
+var (
+	// ErrNoPath is returned by FindPath when every way to the tile is blocked
+	ErrNoPath = errors.New("no free path to the tile")
+	// ErrSameTile is returned by FindPath for the tile the piece is already on
+	ErrSameTile = errors.New("the piece is already on the tile")
+)
+
+// FindPath is the shortest list of moves that takes the piece on from to the
+// tile to, one neighbouring tile at a time over empty tiles. When to is taken
+// the last move is whatever the piece does to it from next to it, such as an
+// attack or a merge. A 6 spawns rather than moves onto an empty tile, so it
+// can only act on its neighbours. Each move costs an action, and like
+// Classify it does not check whose turn it is or if they have the actions.
+func FindPath(s State, from, to Position) ([]Move, error) {
+	if !s.Board.InBounds(from) || !s.Board.InBounds(to) {
+		return nil, ErrOutOfBounds
+	}
+	piece, ok := s.PieceAt(from)
+	if !ok {
+		return nil, ErrNoPiece
+	}
+	if from == to {
+		return nil, ErrSameTile
+	}
+	if abs(from.X-to.X)+abs(from.Y-to.Y) == 1 || piece.Value == 6 {
+		kind, err := Classify(s, from, to)
+		if err != nil {
+			return nil, err
+		}
+		return []Move{{Kind: kind, From: from, To: to}}, nil
+	}
+
+	// breadth first over the empty tiles, so the first way found is the shortest
+	_, occupied := s.PieceAt(to)
+	previous := map[Position]Position{from: NoPosition}
+	queue := []Position{from}
+	last := NoPosition
+	for len(queue) > 0 && last == NoPosition {
+		p := queue[0]
+		queue = queue[1:]
+		for _, d := range Directions {
+			next := Position{X: p.X + d.X, Y: p.Y + d.Y}
+			if next == to && !occupied {
+				previous[to] = p
+				last = to
+				break
+			}
+			if next == to && p != from {
+				// the piece stops next to the taken tile and acts on it from there
+				last = p
+				break
+			}
+			if _, seen := previous[next]; seen || !s.Board.InBounds(next) {
+				continue
+			}
+			if _, taken := s.PieceAt(next); taken {
+				continue
+			}
+			previous[next] = p
+			queue = append(queue, next)
+		}
+	}
+	if last == NoPosition {
+		return nil, ErrNoPath
+	}
+
+	var moves []Move
+	for p := last; p != from; p = previous[p] {
+		moves = append([]Move{{Kind: KindMove, From: previous[p], To: p}}, moves...)
+	}
+	if occupied {
+		// what the piece does to the tile depends only on its value, which moving does not change
+		moved := s.Clone()
+		moved.movePiece(from, last)
+		moved.SyncBoard()
+		kind, err := Classify(moved, last, to)
+		if err != nil {
+			return nil, err
+		}
+		moves = append(moves, Move{Kind: kind, From: last, To: to})
+	}
+	return moves, nil
+}
@@ -0,0 +1,83 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFindPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		pieces   []testPiece
+		from, to Position
+		want     []Move
+		err      error
+	}{
+		{"next to", []testPiece{{0, 1, 1, 1}}, Position{1, 1}, Position{1, 2}, []Move{
+			{KindMove, Position{1, 1}, Position{1, 2}},
+		}, nil},
+		{"straight", []testPiece{{0, 1, 0, 0}}, Position{0, 0}, Position{0, 3}, []Move{
+			{KindMove, Position{0, 0}, Position{0, 1}},
+			{KindMove, Position{0, 1}, Position{0, 2}},
+			{KindMove, Position{0, 2}, Position{0, 3}},
+		}, nil},
+		{"around a piece", []testPiece{{0, 1, 0, 0}, {0, 6, 1, 0}}, Position{0, 0}, Position{2, 0}, []Move{
+			{KindMove, Position{0, 0}, Position{0, 1}},
+			{KindMove, Position{0, 1}, Position{1, 1}},
+			{KindMove, Position{1, 1}, Position{2, 1}},
+			{KindMove, Position{2, 1}, Position{2, 0}},
+		}, nil},
+		{"attack at the end", []testPiece{{0, 2, 0, 0}, {1, 1, 0, 3}}, Position{0, 0}, Position{0, 3}, []Move{
+			{KindMove, Position{0, 0}, Position{0, 1}},
+			{KindMove, Position{0, 1}, Position{0, 2}},
+			{KindAttack, Position{0, 2}, Position{0, 3}},
+		}, nil},
+		{"merge at the end", []testPiece{{0, 1, 0, 0}, {0, 3, 2, 0}}, Position{0, 0}, Position{2, 0}, []Move{
+			{KindMove, Position{0, 0}, Position{1, 0}},
+			{KindMerge, Position{1, 0}, Position{2, 0}},
+		}, nil},
+		{"6 next to", []testPiece{{0, 6, 1, 1}}, Position{1, 1}, Position{2, 1}, []Move{
+			{KindSpawn, Position{1, 1}, Position{2, 1}},
+		}, nil},
+		{"6 further away", []testPiece{{0, 6, 1, 1}}, Position{1, 1}, Position{3, 1}, nil, ErrNotAdjacent},
+		{"gatherer at the end", []testPiece{{0, 1, 0, 0}, {1, 2, 0, 3}}, Position{0, 0}, Position{0, 3}, nil, ErrGathererCannotAttack},
+		{"walled in", []testPiece{{0, 1, 0, 0}, {0, 6, 1, 0}, {1, 6, 0, 1}}, Position{0, 0}, Position{3, 3}, nil, ErrNoPath},
+		{"target walled in", []testPiece{{0, 1, 3, 3}, {1, 6, 0, 1}, {1, 6, 1, 0}}, Position{3, 3}, Position{0, 0}, nil, ErrNoPath},
+		{"same tile", []testPiece{{0, 1, 1, 1}}, Position{1, 1}, Position{1, 1}, nil, ErrSameTile},
+		{"no piece", nil, Position{1, 1}, Position{3, 3}, nil, ErrNoPiece},
+		{"off the board", []testPiece{{0, 1, 1, 1}}, Position{1, 1}, Position{8, 1}, nil, ErrOutOfBounds},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testState(2, 3, tt.pieces...)
+			path, err := FindPath(s, tt.from, tt.to)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("FindPath error = %v, want %v", err, tt.err)
+			}
+			if fmt.Sprint(path) != fmt.Sprint(tt.want) {
+				t.Errorf("FindPath = %v, want %v", path, tt.want)
+			}
+		})
+	}
+}
+
+// TestFindPathPlays makes sure a path found is one Apply accepts move by move
+func TestFindPathPlays(t *testing.T) {
+	s := testState(2, 10, testPiece{0, 4, 0, 0}, testPiece{0, 6, 1, 0}, testPiece{0, 6, 1, 2}, testPiece{1, 3, 4, 4})
+	path, err := FindPath(s, Position{0, 0}, Position{4, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) != 8 {
+		t.Errorf("path is %v moves, want 8", len(path))
+	}
+	for _, m := range path {
+		if s, _, err = Apply(s, m); err != nil {
+			t.Fatalf("%v: %v", m, err)
+		}
+	}
+	if piece, _ := s.PieceAt(Position{4, 4}); piece.PlayerIndex != 0 || piece.Value != 1 {
+		t.Errorf("piece on the target = player %v value %v, want player 0 value 1", piece.PlayerIndex, piece.Value)
+	}
+}
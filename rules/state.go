@@ -0,0 +1,276 @@
+// Package rules holds the sixDivides game state and the rules that change it.
+// It has no rendering or input code, so the client, the server, bots and tools
+// can all run games through it without opening a window.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+type Player struct {
+	Color            color.Color
+	Name             string
+	Pieces           []Piece
+	Actions          int
+	PlayerIndex      int
+	StartingPosition Position
+	// Eliminated is set once the player has lost all of their pieces
+	Eliminated bool
+	// Captured is the number of other players pieces this player has removed
+	Captured int
+}
+
+type Position struct {
+	X int
+	Y int
+}
+
+type Piece struct {
+	Color       color.Color
+	Value       int
+	PlayerIndex int
+	Position    Position
+}
+
+type Tile struct {
+	Position Position
+	Piece    Piece
+}
+
+// Board is the grid of tiles. Width and Height are the highest tile index on
+// each axis, so a board of width 7 has 8 columns.
+type Board struct {
+	Tiles  [][]Tile
+	Width  int
+	Height int
+}
+
+// State is everything needed to carry on a game from any point
+type State struct {
+	Board    Board
+	Players  []Player
+	Turn     int
+	GameOver bool
+	// Winner is the index of the winning player, or NoWinner
+	Winner int
+	// TurnsPlayed counts every turn that has ended
+	TurnsPlayed int
+}
+
+// NoPosition is used wherever a position is not set, such as a piece that has been removed
+var NoPosition = Position{X: -1, Y: -1}
+
+// CreateBoard creates a new board with the given width and height
+func CreateBoard(width int, height int) Board {
+	board := Board{Width: width, Height: height}
+
+	// make the the tiles array and account for the extra row/column for the boarder
+	tiles := make([][]Tile, width+1)
+	for x := 0; x <= width; x++ {
+		tiles[x] = make([]Tile, height+1)
+		for y := 0; y <= height; y++ {
+			tile := Tile{Position: Position{X: x, Y: y}, Piece: Piece{}}
+			tiles[x][y] = tile
+		}
+	}
+	board.Tiles = tiles
+
+	return board
+}
+
+// InBounds reports if the position is a tile on the board
+func (b Board) InBounds(p Position) bool {
+	return p.X >= 0 && p.Y >= 0 && p.X <= b.Width && p.Y <= b.Height
+}
+
+// the smallest and largest number of tiles across or down a board
+const (
+	MinBoardSize = 6
+	MaxBoardSize = 16
+)
+
+var ErrBoardSize = fmt.Errorf("board must be from %vx%v to %vx%v tiles", MinBoardSize, MinBoardSize, MaxBoardSize, MaxBoardSize)
+
+// CheckBoardSize makes sure a board of the given width and height, the highest
+// tile index as for CreateBoard, is one the game can be played on
+func CheckBoardSize(width int, height int) error {
+	if width+1 < MinBoardSize || height+1 < MinBoardSize || width+1 > MaxBoardSize || height+1 > MaxBoardSize {
+		return ErrBoardSize
+	}
+	return nil
+}
+
+// ParseBoardSize reads a size written as columns x rows, such as 8x8, into the
+// highest tile indexes used by CreateBoard, and checks it with CheckBoardSize
+func ParseBoardSize(size string) (int, int, error) {
+	columns, rows, ok := strings.Cut(size, "x")
+	c, errC := strconv.Atoi(columns)
+	r, errR := strconv.Atoi(rows)
+	if !ok || errC != nil || errR != nil {
+		return 0, 0, fmt.Errorf("invalid board %q, expected columns x rows", size)
+	}
+	if err := CheckBoardSize(c-1, r-1); err != nil {
+		return 0, 0, fmt.Errorf("invalid board %q: %w", size, err)
+	}
+	return c - 1, r - 1, nil
+}
+
+// Sections is the number of corner sections a board has, one for each player
+// that can sit down, in the order CreatePlayers takes them
+const Sections = 4
+
+// ErrSeats is returned by CheckSeats for seats CreatePlayers can not sit down
+var ErrSeats = errors.New("invalid seats")
+
+// CheckSeats makes sure seats, as taken by CreatePlayers, has a section for
+// each corner with players numbered from 1 and none missing or sat twice
+func CheckSeats(seats []int) error {
+	if len(seats) != Sections {
+		return fmt.Errorf("%w: there must be %v sections, not %v", ErrSeats, Sections, len(seats))
+	}
+	used := map[int]bool{}
+	for _, seat := range seats {
+		if seat == -1 {
+			continue
+		}
+		if seat < 1 || seat > Sections || used[seat] {
+			return fmt.Errorf("%w %v", ErrSeats, seats)
+		}
+		used[seat] = true
+	}
+	if len(used) == 0 {
+		return fmt.Errorf("%w: a game needs at least one player", ErrSeats)
+	}
+	for p := 1; p <= len(used); p++ {
+		if !used[p] {
+			return fmt.Errorf("%w %v, players must be numbered from 1 with none missing", ErrSeats, seats)
+		}
+	}
+	return nil
+}
+
+// StartingPositions is where the player in each of the four corner sections
+// starts, one tile in from the corner: top left, bottom left, top right and
+// bottom right
+func StartingPositions(width int, height int) []Position {
+	return []Position{{1, 1}, {1, height - 1}, {width - 1, 1}, {width - 1, height - 1}}
+}
+
+// CreatePlayers makes the players for the four corner sections of a board with
+// the given width and height. Each entry of playerPositions is the 1 based
+// player number sat in that section, or -1 if the section is empty.
+func CreatePlayers(width int, height int, playerPositions []int) []Player {
+
+	numberOfPlayers := 0
+	for _, section := range playerPositions {
+		if section != -1 {
+			numberOfPlayers++
+		}
+	}
+
+	var players []Player = make([]Player, numberOfPlayers)
+	startingPositions := StartingPositions(width, height)
+	var playerColor color.Color
+	var playerName string
+	for i, p := range playerPositions {
+		if p != -1 {
+			switch i {
+			case 0:
+				playerName = fmt.Sprintf("Player%v", p)
+				playerColor = color.RGBA{0x00, 0x00, 0xff, 0xff}
+			case 1:
+				playerName = fmt.Sprintf("Player%v", p)
+				playerColor = color.RGBA{0xff, 0x00, 0x00, 0xff}
+			case 2:
+				playerName = fmt.Sprintf("Player%v", p)
+				playerColor = color.RGBA{0x00, 0xff, 0xff, 0xff}
+			case 3:
+				playerName = fmt.Sprintf("Player%v", p)
+				playerColor = color.RGBA{0xff, 0x00, 0xff, 0xff}
+			}
+			players[p-1] = NewPlayer(playerName, playerColor, startingPositions[i], p-1)
+		}
+	}
+
+	return players
+}
+
+func NewPlayer(name string, playerColor color.Color, startingPosition Position, playerGameIndex int) Player {
+	player := Player{
+		Color:            playerColor,
+		Name:             name,
+		StartingPosition: startingPosition,
+		PlayerIndex:      playerGameIndex,
+		Actions:          0,
+		Pieces:           make([]Piece, 1),
+	}
+
+	startingPiece := Piece{Color: playerColor, Value: 6, PlayerIndex: playerGameIndex, Position: startingPosition}
+	player.Pieces[0] = startingPiece
+	return player
+}
+
+// NewGame sets up the board and players and starts the first players turn
+func NewGame(width int, height int, playerPositions []int) State {
+	s := State{
+		Board:   CreateBoard(width, height),
+		Players: CreatePlayers(width, height, playerPositions),
+		Turn:    0,
+		Winner:  NoWinner,
+	}
+	s.SyncBoard()
+	updatePlayerActions(&s)
+	return s
+}
+
+// Clone returns a deep copy of the state, so changes to it do not leak back into s
+func (s State) Clone() State {
+	c := s
+
+	c.Players = make([]Player, len(s.Players))
+	for i, player := range s.Players {
+		c.Players[i] = player
+		c.Players[i].Pieces = append([]Piece(nil), player.Pieces...)
+	}
+
+	c.Board.Tiles = make([][]Tile, len(s.Board.Tiles))
+	for x, column := range s.Board.Tiles {
+		c.Board.Tiles[x] = append([]Tile(nil), column...)
+	}
+
+	return c
+}
+
+// SyncBoard clears the board of pieces and re-adds them from the players,
+// the players pieces are the source of truth and the board is the lookup
+func (s *State) SyncBoard() {
+	for x, row := range s.Board.Tiles {
+		for y := range row {
+			s.Board.Tiles[x][y].Piece = Piece{}
+		}
+	}
+
+	for _, player := range s.Players {
+		for _, piece := range player.Pieces {
+			s.Board.Tiles[piece.Position.X][piece.Position.Y].Piece = piece
+		}
+	}
+}
+
+// PieceAt returns the piece on the tile, and false if the tile is empty
+func (s State) PieceAt(p Position) (Piece, bool) {
+	if !s.Board.InBounds(p) {
+		return Piece{}, false
+	}
+	piece := s.Board.Tiles[p.X][p.Y].Piece
+	return piece, piece != Piece{}
+}
+
+// CurrentPlayer is the player whose turn it is
+func (s State) CurrentPlayer() Player {
+	return s.Players[s.Turn]
+}
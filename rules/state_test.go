@@ -0,0 +1,74 @@
+package rules
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckSeats(t *testing.T) {
+	tests := []struct {
+		name  string
+		seats []int
+		err   error
+	}{
+		{"two players", []int{-1, 2, 1, -1}, nil},
+		{"four players", []int{4, 3, 2, 1}, nil},
+		{"one player", []int{-1, -1, 1, -1}, nil},
+		{"no players", []int{-1, -1, -1, -1}, ErrSeats},
+		{"missing a player", []int{3, -1, -1, -1}, ErrSeats},
+		{"sat twice", []int{1, 1, -1, -1}, ErrSeats},
+		{"too many sections", []int{1, 2, 3, 4, 5}, ErrSeats},
+		{"too few sections", []int{1, 2}, ErrSeats},
+		{"no sections", nil, ErrSeats},
+		{"player 0", []int{0, 1, -1, -1}, ErrSeats},
+		{"player 5", []int{1, 2, 3, 5}, ErrSeats},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckSeats(tt.seats); !errors.Is(err, tt.err) {
+				t.Errorf("CheckSeats(%v) = %v, want %v", tt.seats, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCheckBoardSize(t *testing.T) {
+	tests := []struct {
+		width, height int
+		err           error
+	}{
+		{7, 7, nil},
+		{5, 15, nil},
+		{4, 7, ErrBoardSize},
+		{7, 16, ErrBoardSize},
+		{-1, -1, ErrBoardSize},
+	}
+	for _, tt := range tests {
+		if err := CheckBoardSize(tt.width, tt.height); !errors.Is(err, tt.err) {
+			t.Errorf("CheckBoardSize(%v, %v) = %v, want %v", tt.width, tt.height, err, tt.err)
+		}
+	}
+}
+
+func TestParseBoardSize(t *testing.T) {
+	tests := []struct {
+		size          string
+		width, height int
+		err           bool
+	}{
+		{"8x8", 7, 7, false},
+		{"6x16", 5, 15, false},
+		{"5x8", 0, 0, true},
+		{"8x17", 0, 0, true},
+		{"8", 0, 0, true},
+		{"8 x 8", 0, 0, true},
+		{"axb", 0, 0, true},
+		{"", 0, 0, true},
+	}
+	for _, tt := range tests {
+		width, height, err := ParseBoardSize(tt.size)
+		if (err != nil) != tt.err || width != tt.width || height != tt.height {
+			t.Errorf("ParseBoardSize(%q) = %v, %v, %v", tt.size, width, height, err)
+		}
+	}
+}
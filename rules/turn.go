@@ -0,0 +1,127 @@
+package rules
+
+// NoWinner is the Winner of a game that is still going, or that ended with nobody able to move
+const NoWinner = -1
+
+// EndTurn passes the turn on to the next player, keeping any actions the
+// current player has left for their next turn
+func EndTurn(s State) State {
+	n := s.Clone()
+	if n.GameOver {
+		return n
+	}
+	passTurn(&n)
+	return n
+}
+
+// usePlayerAction takes one action from the current player and moves the turn
+// on when they run out. It reports if the turn was passed on.
+func usePlayerAction(s *State) bool {
+
+	// check if the player has only 1 action left, if so, the last action was just used
+	if s.Players[s.Turn].Actions > 1 && !s.Players[s.Turn].Eliminated {
+		s.Players[s.Turn].Actions--
+		return false
+	}
+	s.Players[s.Turn].Actions = 0
+
+	passTurn(s)
+	return true
+}
+
+// passTurn moves the turn on to the next player that is still in the game and
+// has actions to use, skipping the others. When nobody can act the game ends
+// in a stalemate, so this always returns.
+func passTurn(s *State) {
+	s.TurnsPlayed++
+
+	if s.GameOver {
+		return
+	}
+
+	// try each player once, coming back round to the current player last
+	for i := 0; i < len(s.Players); i++ {
+		nextTurn(s)
+		if s.Players[s.Turn].Eliminated {
+			continue
+		}
+
+		// Reset the actions for the new player's turn
+		updatePlayerActions(s)
+		if s.Players[s.Turn].Actions > 0 {
+			return
+		}
+		// the player has no actions due to no way to generate actions, and will be skipped
+	}
+
+	// nobody remaining is able to do anything
+	s.GameOver = true
+	s.Winner = NoWinner
+}
+
+func nextTurn(s *State) {
+	if s.Turn == (len(s.Players) - 1) {
+		s.Turn = 0
+	} else {
+		s.Turn++
+	}
+}
+
+// updatePlayerActions adds the actions generated by each of the current players pieces
+func updatePlayerActions(s *State) {
+	s.Players[s.Turn].Actions += ActionsFor(s.Players[s.Turn])
+}
+
+// ActionsFor is the number of actions the player's pieces generate at the start of their turn
+func ActionsFor(player Player) int {
+	actions := 0
+	for _, piece := range player.Pieces {
+		switch piece.Value {
+		case 1:
+			actions += 1
+		case 3:
+			actions += 2
+		case 5, 6:
+			actions += 3
+		}
+	}
+	return actions
+}
+
+// checkEliminations marks players with no pieces left as out of the game, and
+// ends the game when at most one player remains. A game started with a single
+// player only ends when that player can no longer act.
+func checkEliminations(s *State) {
+	remaining := 0
+	last := NoWinner
+	for i := range s.Players {
+		if !s.Players[i].Eliminated && len(s.Players[i].Pieces) == 0 {
+			s.Players[i].Eliminated = true
+			s.Players[i].Actions = 0
+		}
+		if !s.Players[i].Eliminated {
+			remaining++
+			last = i
+		}
+	}
+
+	if remaining == 0 {
+		// the last pieces removed each other
+		s.GameOver = true
+		s.Winner = NoWinner
+	} else if remaining == 1 && len(s.Players) > 1 {
+		s.GameOver = true
+		s.Winner = last
+	}
+}
+
+// Remaining returns the indexes of the players still in the game
+func (s State) Remaining() []int {
+	var remaining []int
+	for i, player := range s.Players {
+		if !player.Eliminated {
+			remaining = append(remaining, i)
+		}
+	}
+	return remaining
+}
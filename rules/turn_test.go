@@ -0,0 +1,94 @@
+package rules
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEliminations(t *testing.T) {
+	tests := []struct {
+		name           string
+		players        int
+		pieces         []testPiece
+		move           Move
+		wantGameOver   bool
+		wantWinner     int
+		wantEliminated []int
+	}{
+		{"last piece taken", 2, []testPiece{{0, 4, 1, 1}, {1, 2, 1, 2}},
+			Move{KindAttack, Position{1, 1}, Position{1, 2}}, true, 0, []int{1}},
+		{"last piece struck", 2, []testPiece{{0, 6, 1, 1}, {1, 1, 1, 2}},
+			Move{KindOutpostStrike, Position{1, 1}, Position{1, 2}}, true, 0, []int{1}},
+		{"attacker lost to a larger piece", 2, []testPiece{{0, 2, 1, 1}, {1, 4, 1, 2}},
+			Move{KindAttack, Position{1, 1}, Position{1, 2}}, true, 1, []int{0}},
+		{"last pieces take each other", 2, []testPiece{{0, 2, 1, 1}, {1, 2, 1, 2}},
+			Move{KindAttack, Position{1, 1}, Position{1, 2}}, true, NoWinner, []int{0, 1}},
+		{"pieces left", 2, []testPiece{{0, 4, 1, 1}, {1, 2, 1, 2}, {1, 6, 6, 6}},
+			Move{KindAttack, Position{1, 1}, Position{1, 2}}, false, NoWinner, nil},
+		{"two players left of three", 3, []testPiece{{0, 4, 1, 1}, {1, 2, 1, 2}, {2, 6, 6, 6}},
+			Move{KindAttack, Position{1, 1}, Position{1, 2}}, false, NoWinner, []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testState(tt.players, 3, tt.pieces...)
+			n, _, err := Apply(s, tt.move)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n.GameOver != tt.wantGameOver || n.Winner != tt.wantWinner {
+				t.Errorf("game over = %v won by %v, want %v won by %v", n.GameOver, n.Winner, tt.wantGameOver, tt.wantWinner)
+			}
+			var eliminated []int
+			for i, player := range n.Players {
+				if player.Eliminated {
+					eliminated = append(eliminated, i)
+				}
+			}
+			if fmt.Sprint(eliminated) != fmt.Sprint(tt.wantEliminated) {
+				t.Errorf("eliminated = %v, want %v", eliminated, tt.wantEliminated)
+			}
+			if _, _, err := Apply(n, EndTurnMove); n.GameOver && err != ErrGameOver {
+				t.Errorf("moving after the game is over gave %v, want %v", err, ErrGameOver)
+			}
+		})
+	}
+}
+
+func TestEndTurn(t *testing.T) {
+	tests := []struct {
+		name         string
+		players      int
+		pieces       []testPiece
+		eliminated   []int
+		wantTurn     int
+		wantGameOver bool
+	}{
+		{"next player", 2, []testPiece{{0, 6, 1, 1}, {1, 6, 6, 6}}, nil, 1, false},
+		{"skips the eliminated", 3, []testPiece{{0, 6, 1, 1}, {2, 6, 6, 6}}, []int{1}, 2, false},
+		{"skips a player without actions", 3, []testPiece{{0, 6, 1, 1}, {1, 2, 1, 6}, {2, 6, 6, 6}}, nil, 2, false},
+		{"back round to the first", 2, []testPiece{{0, 6, 1, 1}, {1, 4, 6, 6}}, nil, 0, false},
+		// only soldiers are left, which make no actions
+		{"nobody can act", 2, []testPiece{{0, 2, 1, 1}, {1, 4, 6, 6}}, nil, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := testState(tt.players, 0, tt.pieces...)
+			for _, i := range tt.eliminated {
+				s.Players[i].Eliminated = true
+			}
+			n, result, err := Apply(s, EndTurnMove)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !result.TurnEnded || n.TurnsPlayed == 0 {
+				t.Errorf("the turn did not end")
+			}
+			if n.Turn != tt.wantTurn || n.GameOver != tt.wantGameOver {
+				t.Errorf("turn = %v game over = %v, want %v and %v", n.Turn, n.GameOver, tt.wantTurn, tt.wantGameOver)
+			}
+			if n.GameOver && n.Winner != NoWinner {
+				t.Errorf("winner = %v of a stalemate", n.Winner)
+			}
+		})
+	}
+}
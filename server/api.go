@@ -0,0 +1,389 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"sixDivides/notation"
+	"sixDivides/online"
+	"sixDivides/rules"
+)
+
+// apiPath is where the JSON API is served, see the README for the routes
+const apiPath = "/api/"
+
+// maxAPIGames is how many games the API keeps, the oldest is dropped to make room for a new one
+const maxAPIGames = 1000
+
+// maxAPIBody is the largest request body the API reads, its requests are all small
+const maxAPIBody = 4 << 10
+
+// ruleErrorCodes name the rules errors, so scripts can check why a move was refused
+var ruleErrorCodes = map[error]string{
+	rules.ErrGameOver:             "game-over",
+	rules.ErrOutOfBounds:          "out-of-bounds",
+	rules.ErrNotAdjacent:          "not-adjacent",
+	rules.ErrNoPiece:              "no-piece",
+	rules.ErrNotYourPiece:         "not-your-piece",
+	rules.ErrNoActions:            "no-actions",
+	rules.ErrOutpostFull:          "outpost-full",
+	rules.ErrCombineTwoSixes:      "combine-two-sixes",
+	rules.ErrCombineOntoOutpost:   "combine-onto-outpost",
+	rules.ErrGathererCannotAttack: "gatherer-cannot-attack",
+	rules.ErrInvalidValue:         "invalid-value",
+	rules.ErrWrongKind:            "wrong-kind",
+	rules.ErrNoPath:               "no-path",
+	rules.ErrSameTile:             "same-tile",
+}
+
+// apiError is the body of every failed request
+type apiError struct {
+	Error apiErrorDetail `json:"error"`
+}
+
+type apiErrorDetail struct {
+	// Code is a fixed name for the kind of error, Message explains it
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Move is the move that was refused, when there was one
+	Move string `json:"move,omitempty"`
+}
+
+// apiGame is a game played through the API, guarded by apiGames
+type apiGame struct {
+	state  rules.State
+	record notation.Record
+	// history is each turn as a numbered line of the record, kept as the moves
+	// are played so the record does not have to be written out for every request
+	history []string
+	// turnOpen is set while the last line of history is the turn being played
+	turnOpen bool
+}
+
+// apiGames is every game created through the API, by id
+type apiGames struct {
+	mu    sync.Mutex
+	games map[string]*apiGame
+	// order is the ids from oldest to newest, next is the id of the next game
+	order []string
+	next  int
+}
+
+func newAPIGames() *apiGames {
+	return &apiGames{games: map[string]*apiGame{}, next: 1}
+}
+
+// apiState is a game as returned by the API
+type apiState struct {
+	ID string `json:"id"`
+	online.View
+	GameOver bool `json:"gameOver"`
+	// Moves is the number of moves played so far
+	Moves int `json:"moves"`
+}
+
+// apiMove is a move that can be played, or was
+type apiMove struct {
+	Move string `json:"move"`
+	Kind string `json:"kind"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// apiMoveRequest is the body of a posted move, either Move in notation, or the
+// From and To tiles leaving the rules to work out the kind
+type apiMoveRequest struct {
+	Move string `json:"move"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// apiMoveResult is the reply to a posted move
+type apiMoveResult struct {
+	Played    apiMove  `json:"played"`
+	TurnEnded bool     `json:"turnEnded"`
+	State     apiState `json:"state"`
+}
+
+// apiNewGame is the body of a request to create a game, anything left out is the same as a new game in the client
+type apiNewGame struct {
+	Width  int   `json:"width"`
+	Height int   `json:"height"`
+	Seats  []int `json:"seats"`
+}
+
+// serveAPI routes the API requests:
+//
+//	POST /api/games                 create a game
+//	GET  /api/games/{id}            the state of the game
+//	GET  /api/games/{id}/moves      the moves the current player can make
+//	POST /api/games/{id}/moves      play a move
+//	GET  /api/games/{id}/history    the moves so far, and the game in notation
+func serveAPI(games *apiGames) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, apiPath), "/"), "/")
+		if parts[0] != "games" || len(parts) > 3 {
+			writeAPIError(w, http.StatusNotFound, "not-found", "no such route, see the README for the API", "")
+			return
+		}
+
+		route := ""
+		if len(parts) == 3 {
+			route = parts[2]
+		}
+		switch {
+		case len(parts) == 1 && r.Method == http.MethodPost:
+			games.create(w, r)
+		case len(parts) == 1:
+			writeMethodNotAllowed(w, http.MethodPost)
+		case route == "" && r.Method == http.MethodGet:
+			games.get(w, parts[1])
+		case route == "moves" && r.Method == http.MethodGet:
+			games.legalMoves(w, parts[1])
+		case route == "moves" && r.Method == http.MethodPost:
+			games.play(w, r, parts[1])
+		case route == "history" && r.Method == http.MethodGet:
+			games.history(w, parts[1])
+		case route == "" || route == "history":
+			writeMethodNotAllowed(w, http.MethodGet)
+		case route == "moves":
+			writeMethodNotAllowed(w, http.MethodGet+", "+http.MethodPost)
+		default:
+			writeAPIError(w, http.StatusNotFound, "not-found", "no such route, see the README for the API", "")
+		}
+	}
+}
+
+func (games *apiGames) create(w http.ResponseWriter, r *http.Request) {
+	settings := apiNewGame{Width: 7, Height: 7, Seats: []int{-1, 2, 1, -1}}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBody)).Decode(&settings); err != nil && !errors.Is(err, io.EOF) {
+		writeBodyError(w, "could not read the game settings", err)
+		return
+	}
+	// the record checks the board and seats, which rules.NewGame takes as they are
+	record, err := notation.NewRecord(settings.Width, settings.Height, settings.Seats)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad-game", err.Error(), "")
+		return
+	}
+
+	game := &apiGame{
+		state:  rules.NewGame(settings.Width, settings.Height, settings.Seats),
+		record: record,
+	}
+
+	games.mu.Lock()
+	defer games.mu.Unlock()
+	id := strconv.Itoa(games.next)
+	games.next++
+	games.games[id] = game
+	games.order = append(games.order, id)
+	if len(games.order) > maxAPIGames {
+		delete(games.games, games.order[0])
+		games.order = games.order[1:]
+	}
+	log.Printf("api game %v created", id)
+	writeJSON(w, http.StatusCreated, game.apiState(id))
+}
+
+// find looks up the game, writing the error when there is not one, the games must be locked
+func (games *apiGames) find(w http.ResponseWriter, id string) *apiGame {
+	game := games.games[id]
+	if game == nil {
+		writeAPIError(w, http.StatusNotFound, "no-game", fmt.Sprintf("there is no game %q", id), "")
+	}
+	return game
+}
+
+func (games *apiGames) get(w http.ResponseWriter, id string) {
+	games.mu.Lock()
+	defer games.mu.Unlock()
+	game := games.find(w, id)
+	if game == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, game.apiState(id))
+}
+
+// legalMoves lists every move the current player can make, including ending their turn
+func (games *apiGames) legalMoves(w http.ResponseWriter, id string) {
+	games.mu.Lock()
+	defer games.mu.Unlock()
+	game := games.find(w, id)
+	if game == nil {
+		return
+	}
+
+	moves := []apiMove{}
+	for _, m := range rules.LegalMoves(game.state, game.state.Turn) {
+		moves = append(moves, newAPIMove(m))
+	}
+	if !game.state.GameOver {
+		moves = append(moves, newAPIMove(rules.EndTurnMove))
+	}
+	writeJSON(w, http.StatusOK, moves)
+}
+
+func (games *apiGames) play(w http.ResponseWriter, r *http.Request, id string) {
+	var request apiMoveRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBody)).Decode(&request); err != nil {
+		writeBodyError(w, "could not read the move", err)
+		return
+	}
+	m, err := request.parse()
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "bad-move", err.Error(), request.Move)
+		return
+	}
+
+	games.mu.Lock()
+	defer games.mu.Unlock()
+	game := games.find(w, id)
+	if game == nil {
+		return
+	}
+
+	n, result, err := rules.Apply(game.state, m)
+	if err != nil {
+		code := "illegal-move"
+		for rule, name := range ruleErrorCodes {
+			if errors.Is(err, rule) {
+				code = name
+			}
+		}
+		status := http.StatusUnprocessableEntity
+		if errors.Is(err, rules.ErrGameOver) {
+			status = http.StatusConflict
+		}
+		writeAPIError(w, status, code, err.Error(), request.describe())
+		return
+	}
+	m.Kind = result.Kind
+	text, err := notation.FormatMove(m)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "internal", err.Error(), "")
+		return
+	}
+	game.state = n
+	game.record.Moves = append(game.record.Moves, m)
+	game.addHistory(text, result.TurnEnded)
+
+	writeJSON(w, http.StatusOK, apiMoveResult{Played: newAPIMove(m), TurnEnded: result.TurnEnded, State: game.apiState(id)})
+}
+
+func (games *apiGames) history(w http.ResponseWriter, id string) {
+	games.mu.Lock()
+	defer games.mu.Unlock()
+	game := games.find(w, id)
+	if game == nil {
+		return
+	}
+	text, err := game.record.MarshalText()
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "internal", err.Error(), "")
+		return
+	}
+
+	moves := []apiMove{}
+	for _, m := range game.record.Moves {
+		moves = append(moves, newAPIMove(m))
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Moves  []apiMove `json:"moves"`
+		Record string    `json:"record"`
+	}{moves, string(text)})
+}
+
+// addHistory adds the move, in notation, to the line of its turn, numbering
+// the turns the same as MarshalText does
+func (game *apiGame) addHistory(move string, turnEnded bool) {
+	if game.turnOpen {
+		game.history[len(game.history)-1] += " " + move
+	} else {
+		game.history = append(game.history, fmt.Sprintf("%d. %s", len(game.history)+1, move))
+	}
+	game.turnOpen = !turnEnded
+}
+
+func (game *apiGame) apiState(id string) apiState {
+	return apiState{
+		ID:       id,
+		View:     *stateView(game.state, game.history),
+		GameOver: game.state.GameOver,
+		Moves:    len(game.record.Moves),
+	}
+}
+
+// parse works out the move from whichever way the request gave it
+func (request apiMoveRequest) parse() (rules.Move, error) {
+	if request.Move != "" {
+		return notation.ParseMove(request.Move)
+	}
+	if request.From == "" || request.To == "" {
+		return rules.Move{}, errors.New(`give the move in notation as "move", or the tiles as "from" and "to"`)
+	}
+	from, err := notation.ParsePosition(request.From)
+	if err != nil {
+		return rules.Move{}, err
+	}
+	to, err := notation.ParsePosition(request.To)
+	if err != nil {
+		return rules.Move{}, err
+	}
+	return rules.Move{Kind: rules.KindAny, From: from, To: to}, nil
+}
+
+// describe is the move as the request gave it, for error messages
+func (request apiMoveRequest) describe() string {
+	if request.Move != "" {
+		return request.Move
+	}
+	return request.From + " " + request.To
+}
+
+func newAPIMove(m rules.Move) apiMove {
+	text, err := notation.FormatMove(m)
+	if err != nil {
+		text = m.String()
+	}
+	played := apiMove{Move: text, Kind: m.Kind.String()}
+	if m.Kind != rules.KindEndTurn {
+		played.From = notation.FormatPosition(m.From)
+		played.To = notation.FormatPosition(m.To)
+	}
+	return played
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("could not write the api response: %v", err)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, status int, code string, message string, move string) {
+	writeJSON(w, status, apiError{apiErrorDetail{Code: code, Message: message, Move: move}})
+}
+
+// writeBodyError is the reply to a request body that could not be read
+func writeBodyError(w http.ResponseWriter, message string, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeAPIError(w, http.StatusRequestEntityTooLarge, "too-large", fmt.Sprintf("%v: the body is over %v bytes", message, tooLarge.Limit), "")
+		return
+	}
+	writeAPIError(w, http.StatusBadRequest, "bad-request", message+": "+err.Error(), "")
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, allowed string) {
+	w.Header().Set("Allow", allowed)
+	writeAPIError(w, http.StatusMethodNotAllowed, "method-not-allowed", "use "+allowed, "")
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"sixDivides/notation"
+	"sixDivides/rules"
+)
+
+// apiRequest sends the request to the API, and decodes the reply into body when it is not nil
+func apiRequest(t *testing.T, api http.Handler, method string, path string, request string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(request)))
+	if body != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), body); err != nil {
+			t.Fatalf("%v %v: could not read %q: %v", method, path, w.Body.String(), err)
+		}
+	}
+	return w
+}
+
+// newAPIGame creates a 6x6 game with two players in the left corners, b2 and b5
+func newAPIGame(t *testing.T, api http.Handler) apiState {
+	t.Helper()
+	var state apiState
+	w := apiRequest(t, api, http.MethodPost, "/api/games", `{"width":5,"height":5,"seats":[1,2,-1,-1]}`, &state)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create = %v %v", w.Code, w.Body.String())
+	}
+	return state
+}
+
+func TestAPIRoutes(t *testing.T) {
+	api := serveAPI(newAPIGames())
+	newAPIGame(t, api)
+
+	tests := []struct {
+		method, path string
+		body         string
+		status       int
+		code         string
+		allow        string
+	}{
+		{http.MethodPost, "/api/games", "", http.StatusCreated, "", ""},
+		{http.MethodGet, "/api/games/1", "", http.StatusOK, "", ""},
+		{http.MethodGet, "/api/games/1/moves", "", http.StatusOK, "", ""},
+		{http.MethodGet, "/api/games/1/history", "", http.StatusOK, "", ""},
+		{http.MethodGet, "/api/nothing", "", http.StatusNotFound, "not-found", ""},
+		{http.MethodGet, "/api/games/1/nothing", "", http.StatusNotFound, "not-found", ""},
+		{http.MethodGet, "/api/games/1/moves/2", "", http.StatusNotFound, "not-found", ""},
+		{http.MethodGet, "/api/games/99", "", http.StatusNotFound, "no-game", ""},
+		{http.MethodPost, "/api/games/99/moves", `{"move":"pass"}`, http.StatusNotFound, "no-game", ""},
+		{http.MethodGet, "/api/games", "", http.StatusMethodNotAllowed, "method-not-allowed", "POST"},
+		{http.MethodDelete, "/api/games/1", "", http.StatusMethodNotAllowed, "method-not-allowed", "GET"},
+		{http.MethodPost, "/api/games/1/history", "", http.StatusMethodNotAllowed, "method-not-allowed", "GET"},
+		{http.MethodPut, "/api/games/1/moves", "", http.StatusMethodNotAllowed, "method-not-allowed", "GET, POST"},
+		{http.MethodPost, "/api/games", `{"seats":[1,1,-1,-1]}`, http.StatusBadRequest, "bad-game", ""},
+		{http.MethodPost, "/api/games", `{"width":40,"height":40}`, http.StatusBadRequest, "bad-game", ""},
+		{http.MethodPost, "/api/games", `{"width":`, http.StatusBadRequest, "bad-request", ""},
+		{http.MethodPost, "/api/games", `{"seats":[` + strings.Repeat("-1,", maxAPIBody) + `1]}`, http.StatusRequestEntityTooLarge, "too-large", ""},
+		{http.MethodPost, "/api/games/1/moves", `{"move":"` + strings.Repeat("a", maxAPIBody) + `"}`, http.StatusRequestEntityTooLarge, "too-large", ""},
+		{http.MethodPost, "/api/games/1/moves", `{}`, http.StatusBadRequest, "bad-move", ""},
+		{http.MethodPost, "/api/games/1/moves", `{"move":"b2?b3"}`, http.StatusBadRequest, "bad-move", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := apiRequest(t, api, tt.method, tt.path, tt.body, nil)
+			var reply apiError
+			if w.Code >= http.StatusBadRequest {
+				if err := json.Unmarshal(w.Body.Bytes(), &reply); err != nil {
+					t.Fatalf("could not read the error %q: %v", w.Body.String(), err)
+				}
+			}
+			if w.Code != tt.status || reply.Error.Code != tt.code {
+				t.Errorf("reply = %v %q, want %v %q: %v", w.Code, reply.Error.Code, tt.status, tt.code, w.Body.String())
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.allow {
+				t.Errorf("Allow = %q, want %q", allow, tt.allow)
+			}
+		})
+	}
+}
+
+func TestAPIPlay(t *testing.T) {
+	api := serveAPI(newAPIGames())
+	game := newAPIGame(t, api)
+	path := "/api/games/" + game.ID + "/moves"
+
+	// Player1 on b2 spends their three actions, the last ending the turn
+	turn := []struct {
+		request   string
+		played    string
+		turnEnded bool
+	}{
+		{`{"move":"b2*b3"}`, "b2*b3", false},
+		{`{"from":"b2","to":"c2"}`, "b2*c2", false},
+		{`{"move":"c2-d2"}`, "c2-d2", true},
+	}
+	for _, tt := range turn {
+		var result apiMoveResult
+		w := apiRequest(t, api, http.MethodPost, path, tt.request, &result)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%v = %v %v", tt.request, w.Code, w.Body.String())
+		}
+		if result.Played.Move != tt.played || result.TurnEnded != tt.turnEnded {
+			t.Errorf("%v played %v, turn ended %v, want %v and %v", tt.request, result.Played.Move, result.TurnEnded, tt.played, tt.turnEnded)
+		}
+	}
+
+	// Player2 spawns a 1 next to Player1s 1 on b3, which can not attack it
+	var result apiMoveResult
+	if w := apiRequest(t, api, http.MethodPost, path, `{"move":"b5*b4"}`, &result); w.Code != http.StatusOK || result.State.Turn != 1 {
+		t.Fatalf("b5*b4 = %v %v", w.Code, w.Body.String())
+	}
+
+	illegal := []struct {
+		request string
+		status  int
+		code    string
+	}{
+		{`{"move":"b4xb3"}`, http.StatusUnprocessableEntity, "gatherer-cannot-attack"},
+		{`{"from":"b4","to":"b3"}`, http.StatusUnprocessableEntity, "gatherer-cannot-attack"},
+		{`{"move":"b5-b6"}`, http.StatusUnprocessableEntity, "wrong-kind"},
+		{`{"move":"b3-a3"}`, http.StatusUnprocessableEntity, "not-your-piece"},
+		{`{"move":"e5-e6"}`, http.StatusUnprocessableEntity, "no-piece"},
+		{`{"move":"b4-b6"}`, http.StatusUnprocessableEntity, "not-adjacent"},
+		{`{"move":"a4-z4"}`, http.StatusUnprocessableEntity, "out-of-bounds"},
+	}
+	for _, tt := range illegal {
+		t.Run(tt.request, func(t *testing.T) {
+			var reply apiError
+			w := apiRequest(t, api, http.MethodPost, path, tt.request, &reply)
+			if w.Code != tt.status || reply.Error.Code != tt.code {
+				t.Errorf("reply = %v %q, want %v %q: %v", w.Code, reply.Error.Code, tt.status, tt.code, w.Body.String())
+			}
+			if reply.Error.Move == "" {
+				t.Errorf("the refused move is not in the error")
+			}
+		})
+	}
+
+	// the refused moves changed nothing
+	var state apiState
+	apiRequest(t, api, http.MethodGet, "/api/games/"+game.ID, "", &state)
+	if state.Moves != 4 || state.Turn != 1 {
+		t.Errorf("after the refused moves there are %v moves on turn %v, want 4 on turn 1", state.Moves, state.Turn)
+	}
+}
+
+// TestAPIHistory plays a game long enough for many turns, and checks the
+// history kept as it goes matches the record written out in full
+func TestAPIHistory(t *testing.T) {
+	api := serveAPI(newAPIGames())
+	game := newAPIGame(t, api)
+	path := "/api/games/" + game.ID
+
+	var state apiState
+	for i := 0; i < 60; i++ {
+		var moves []apiMove
+		apiRequest(t, api, http.MethodGet, path+"/moves", "", &moves)
+		if len(moves) == 0 {
+			break
+		}
+		// a different move each time, pass being last, so turns end early as well as by running out
+		m := moves[i%len(moves)]
+		var result apiMoveResult
+		if w := apiRequest(t, api, http.MethodPost, path+"/moves", fmt.Sprintf(`{"move":%q}`, m.Move), &result); w.Code != http.StatusOK {
+			t.Fatalf("%v = %v %v", m.Move, w.Code, w.Body.String())
+		}
+		state = result.State
+	}
+
+	var history struct {
+		Moves  []apiMove `json:"moves"`
+		Record string    `json:"record"`
+	}
+	apiRequest(t, api, http.MethodGet, path+"/history", "", &history)
+	var lines []string
+	for _, line := range strings.Split(history.Record, "\n") {
+		if line != "" && line[0] >= '0' && line[0] <= '9' {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) < 5 || fmt.Sprint(state.History) != fmt.Sprint(lines) {
+		t.Errorf("history = %q, want the turns of the record %q", state.History, lines)
+	}
+
+	record, err := notation.ParseRecord(history.Record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(record.Moves) != len(history.Moves) || len(record.Moves) != state.Moves {
+		t.Errorf("the record has %v moves, the history %v and the state %v", len(record.Moves), len(history.Moves), state.Moves)
+	}
+	s, err := record.Replay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Turn != state.Turn || notation.Result(s) != state.Result {
+		t.Errorf("the record replays to turn %v %v, the state is turn %v %v", s.Turn, notation.Result(s), state.Turn, state.Result)
+	}
+}
+
+// TestRuleErrorCodes makes sure every rules error has its own code
+func TestRuleErrorCodes(t *testing.T) {
+	seen := map[string]bool{}
+	for rule, code := range ruleErrorCodes {
+		if seen[code] || code == "" {
+			t.Errorf("%v has code %q, which is empty or used twice", rule, code)
+		}
+		seen[code] = true
+	}
+	if _, ok := ruleErrorCodes[rules.ErrSameTile]; !ok {
+		t.Errorf("ErrSameTile has no code")
+	}
+}
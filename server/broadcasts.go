@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"sixDivides/notation"
+	"sixDivides/online"
+	"sixDivides/rules"
+)
+
+// maxBroadcastDelay is the longest a broadcast can keep its watchers behind the game
+const maxBroadcastDelay = 10 * time.Minute
+
+// maxRecordMoves is the most moves a broadcast record can have, along with
+// online.MaxRecordLength, as the server replays all of it for every update
+const maxRecordMoves = 10000
+
+var errNoBroadcast = errors.New("there is no broadcast with that code")
+
+// broadcast is a game played at a client, re-sent to the clients watching it.
+// It is guarded by the hub.
+type broadcast struct {
+	code      string
+	name      string
+	delay     time.Duration
+	publisher *client
+	watchers  map[*client]bool
+	// updates are the views not yet shown to the watchers, after the one they are shown now
+	updates []update
+	// sent is the seq of the last update sent to the watchers
+	sent int
+	seq  int
+}
+
+// update is a view of the game, and when the publisher sent it
+type update struct {
+	seq  int
+	at   time.Time
+	view *online.View
+}
+
+// publish updates the clients broadcast with the game, starting the broadcast the first time
+func (h *hub) publish(c *client, msg online.ClientMessage) error {
+	view, err := newView(msg.Record)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	b := c.publishing
+	if b == nil {
+		b = &broadcast{
+			publisher: c,
+			name:      msg.Name,
+			delay:     min(max(time.Duration(msg.Delay)*time.Second, 0), maxBroadcastDelay),
+			watchers:  map[*client]bool{},
+		}
+		for i := 0; i < codeAttempts && b.code == ""; i++ {
+			if code := randomCode(); h.broadcasts[code] == nil {
+				b.code = code
+			}
+		}
+		if b.code == "" {
+			return errors.New("the server has too many broadcasts, try again later")
+		}
+		if b.name == "" {
+			b.name = "Game " + b.code
+		}
+		h.broadcasts[b.code] = b
+		c.publishing = b
+		c.deliver(online.ServerMessage{Type: online.TypeBroadcasting, Room: b.code, Player: online.Spectator})
+		log.Printf("broadcast %v started, delayed %v", b.code, b.delay)
+	}
+
+	b.seq++
+	u := update{seq: b.seq, at: time.Now(), view: view}
+	b.updates = append(b.updates, u)
+	if b.delay == 0 {
+		b.send(u)
+	} else {
+		time.AfterFunc(b.delay, func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			b.send(u)
+		})
+	}
+	return nil
+}
+
+// send shows the watchers the update, unless they have already been shown a later one
+func (b *broadcast) send(u update) {
+	if u.seq <= b.sent {
+		return
+	}
+	b.sent = u.seq
+	for len(b.updates) > 1 && b.updates[1].seq <= u.seq {
+		b.updates = b.updates[1:]
+	}
+	for c := range b.watchers {
+		c.deliver(online.ServerMessage{Type: online.TypeView, Room: b.code, Player: online.Spectator, View: u.view})
+	}
+}
+
+// current is the view the watchers are being shown, nil until the delay has passed for the first one
+func (b *broadcast) current() *online.View {
+	if len(b.updates) == 0 || b.updates[0].seq > b.sent {
+		return nil
+	}
+	return b.updates[0].view
+}
+
+// watch sends the client the broadcast with the code, and every change to it from now on
+func (h *hub) watch(c *client, code string) error {
+	if c.room != nil {
+		return fmt.Errorf("already in room %v", c.room.code)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	b := h.broadcasts[code]
+	if b == nil {
+		return errNoBroadcast
+	}
+	if c.watching != nil {
+		delete(c.watching.watchers, c)
+	}
+	c.watching = b
+	b.watchers[c] = true
+	if view := b.current(); view != nil {
+		c.deliver(online.ServerMessage{Type: online.TypeView, Room: b.code, Player: online.Spectator, View: view})
+	}
+	return nil
+}
+
+// stopBroadcasts ends the clients broadcast, and stops it watching one
+func (h *hub) stopBroadcasts(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if w := c.watching; w != nil {
+		delete(w.watchers, c)
+		c.watching = nil
+	}
+	b := c.publishing
+	if b == nil {
+		return
+	}
+	c.publishing = nil
+	delete(h.broadcasts, b.code)
+	log.Printf("broadcast %v ended", b.code)
+
+	// the watchers are told once they have seen the rest of the game
+	time.AfterFunc(b.delay, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		for w := range b.watchers {
+			w.deliver(online.ServerMessage{Type: online.TypeNotice, Room: b.code, Player: online.Spectator, Notice: "the broadcast has ended"})
+		}
+	})
+}
+
+// serveBroadcasts lists the games being broadcast
+func serveBroadcasts(h *hub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.mu.Lock()
+		list := []online.Broadcast{}
+		for _, b := range h.broadcasts {
+			listed := online.Broadcast{Code: b.code, Name: b.name, Delay: int(b.delay / time.Second), Watching: len(b.watchers)}
+			if view := b.current(); view != nil {
+				for _, player := range view.Players {
+					listed.Players = append(listed.Players, player.Name)
+				}
+				listed.Turn = len(view.History)
+			}
+			list = append(list, listed)
+		}
+		h.mu.Unlock()
+
+		sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(list); err != nil {
+			log.Printf("could not list the broadcasts: %v", err)
+		}
+	}
+}
+
+// newView works out the position a record leads to, for pages that do not know
+// the rules. ParseRecord checks the board and seats, so the replay can set up the game.
+func newView(text string) (*online.View, error) {
+	if len(text) > online.MaxRecordLength {
+		return nil, fmt.Errorf("the record is longer than %v bytes", online.MaxRecordLength)
+	}
+	record, err := notation.ParseRecord(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(record.Moves) > maxRecordMoves {
+		return nil, fmt.Errorf("the record has more than %v moves", maxRecordMoves)
+	}
+	s, err := record.Replay()
+	if err != nil {
+		return nil, err
+	}
+	// the turns are the numbered lines of the record
+	var history []string
+	for _, line := range strings.Split(text, "\n") {
+		if line != "" && line[0] >= '0' && line[0] <= '9' {
+			history = append(history, line)
+		}
+	}
+	return stateView(s, history), nil
+}
+
+// stateView describes the state, history is each turn that led to it as
+// numbered in a record
+func stateView(s rules.State, history []string) *online.View {
+	view := &online.View{
+		Columns: s.Board.Width + 1,
+		Rows:    s.Board.Height + 1,
+		Turn:    s.Turn,
+		Result:  notation.Result(s),
+		History: append([]string{}, history...),
+	}
+	for _, player := range s.Players {
+		view.Players = append(view.Players, online.ViewPlayer{
+			Name:       player.Name,
+			Color:      cssColor(player.Color),
+			Actions:    player.Actions,
+			Eliminated: player.Eliminated,
+		})
+		for _, piece := range player.Pieces {
+			view.Pieces = append(view.Pieces, online.ViewPiece{X: piece.Position.X, Y: piece.Position.Y, Value: piece.Value, Player: piece.PlayerIndex})
+		}
+	}
+	return view
+}
+
+func cssColor(c color.Color) string {
+	if c == nil {
+		return "#ffffff"
+	}
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
@@ -38,7 +38,7 @@ for (( i=0; i<$length; i++ )); do
     fi
 
     # Build the executable
-    go build -o "$GOOS-$GOARCH$BIN_EXT ../main.go"
+    go build -o "$GOOS-$GOARCH$BIN_EXT" ..
 done
 
 echo "Build completed."
\ No newline at end of file
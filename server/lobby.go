@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+
+	"sixDivides/notation"
+	"sixDivides/online"
+	"sixDivides/rules"
+)
+
+var (
+	errNotHost   = errors.New("only the host can start the game")
+	errNotSeated = errors.New("sit in a section first")
+	errTaken     = errors.New("that section is taken")
+)
+
+// codeAttempts is how many random join codes are tried before the server is treated as full
+const codeAttempts = 100
+
+// create makes a new room with a fresh join code, and the client as its host
+func (h *hub) create(c *client, msg online.ClientMessage) error {
+	if c.room != nil {
+		return fmt.Errorf("already in room %v", c.room.code)
+	}
+	if c.publishing != nil || c.watching != nil {
+		return errors.New("already broadcasting or watching a broadcast")
+	}
+	if err := checkBoard(msg.Width, msg.Height); err != nil {
+		return err
+	}
+
+	r := &room{
+		hub:     h,
+		host:    c,
+		width:   msg.Width,
+		height:  msg.Height,
+		clients: map[*client]bool{},
+	}
+
+	h.mu.Lock()
+	for i := 0; i < codeAttempts && r.code == ""; i++ {
+		code := randomCode()
+		if _, taken := h.rooms[code]; !taken {
+			r.code = code
+		}
+	}
+	if r.code == "" {
+		h.mu.Unlock()
+		return errors.New("the server has too many rooms, try again later")
+	}
+	h.rooms[r.code] = r
+	r.mu.Lock()
+	h.mu.Unlock()
+	defer r.mu.Unlock()
+
+	log.Printf("room %v created, %vx%v", r.code, r.width+1, r.height+1)
+	return r.enter(c, msg.Name)
+}
+
+// seat moves the client into the section, or out of the sections for online.NoSection
+func (r *room) seat(c *client, section int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started {
+		return errStarted
+	}
+	if section < online.NoSection || section >= online.Sections {
+		return fmt.Errorf("there is no section %v", section)
+	}
+	if section != online.NoSection && r.sections[section] != nil && r.sections[section] != c {
+		return errTaken
+	}
+
+	if c.section != online.NoSection {
+		r.sections[c.section] = nil
+	}
+	c.section = section
+	c.ready = false
+	if section != online.NoSection {
+		r.sections[section] = c
+	}
+	r.broadcastLobby()
+	return nil
+}
+
+// setReady marks the client as ready to play, or not
+func (r *room) setReady(c *client, ready bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started {
+		return errStarted
+	}
+	if c.section == online.NoSection {
+		return errNotSeated
+	}
+	c.ready = ready
+	r.broadcastLobby()
+	return nil
+}
+
+// start begins the game with a player for each section that has someone in
+// it, numbered in section order. Everyone other than the host has to be
+// ready, the host starting it is them being ready.
+func (r *room) start(c *client) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started {
+		return errStarted
+	}
+	if c != r.host {
+		return errNotHost
+	}
+
+	seats := make([]int, online.Sections)
+	players := 0
+	for i, sat := range r.sections {
+		seats[i] = -1
+		if sat == nil {
+			continue
+		}
+		if !sat.ready && sat != r.host {
+			return fmt.Errorf("%v is not ready", sat.name)
+		}
+		players++
+		seats[i] = players
+	}
+	record, err := notation.NewRecord(r.width, r.height, seats)
+	if err != nil {
+		return err
+	}
+
+	r.started = true
+	r.state = rules.NewGame(r.width, r.height, seats)
+	r.record = record
+	r.sessions = make([]*session, players)
+	for i, sat := range r.sections {
+		if sat != nil {
+			sat.player = seats[i] - 1
+			r.sessions[sat.player] = &session{client: sat, name: sat.name, token: newToken()}
+		}
+	}
+	log.Printf("room %v started with %v players", r.code, players)
+
+	for other := range r.clients {
+		if err := r.sendGame(other); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// broadcastLobby sends everyone in the room how it looks to them, the room must be locked
+func (r *room) broadcastLobby() {
+	for c := range r.clients {
+		lobby := &online.Lobby{
+			Width:  r.width,
+			Height: r.height,
+			Seats:  make([]online.Seat, online.Sections),
+			You:    c.section,
+			Host:   c == r.host,
+		}
+		for i, sat := range r.sections {
+			if sat != nil {
+				lobby.Seats[i] = online.Seat{Name: sat.name, Ready: sat.ready, Host: sat == r.host}
+			}
+		}
+		lobby.Watching = len(r.clients)
+		for _, seat := range lobby.Seats {
+			if seat.Name != "" {
+				lobby.Watching--
+			}
+		}
+		c.deliver(online.ServerMessage{Type: online.TypeLobby, Room: r.code, Player: online.Spectator, Lobby: lobby})
+	}
+}
+
+// nextHost picks who takes over from a host that left, preferring someone sat in a section
+func nextHost(r *room) *client {
+	for _, sat := range r.sections {
+		if sat != nil {
+			return sat
+		}
+	}
+	for c := range r.clients {
+		return c
+	}
+	return nil
+}
+
+// randomCode is a join code, that may already be taken
+func randomCode() string {
+	limit := 1
+	for i := 0; i < online.CodeLength; i++ {
+		limit *= 10
+	}
+	return fmt.Sprintf("%0*d", online.CodeLength, rand.Intn(limit))
+}
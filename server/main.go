@@ -2,8 +2,15 @@ package main
 
 import (
 	"embed"
+	"flag"
 	"fmt"
+	"log"
 	"net/http"
+	"strings"
+	"time"
+
+	"sixDivides/bot"
+	"sixDivides/online"
 )
 
 // https://golangbot.com/webassembly-using-go/
@@ -26,10 +33,27 @@ var staticFiles embed.FS
 
 func main() {
 	port := "9090"
+	grace := flag.Duration("grace", time.Minute, "how long a player who lost their connection has to come back")
+	absent := flag.String("absent", absentSkip, "what happens to the turns of a player who did not come back, skip or a bot kind: "+strings.Join(bot.Kinds, ", ")+", or "+bot.ExternalPrefix+"command for an external bot")
+	flag.Parse()
+	if *absent != absentSkip {
+		b, err := bot.New(*absent, 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+		bot.Close(b)
+	}
 
 	done := make(chan bool)
 	//go http.ListenAndServe(fmt.Sprintf(":%v", port), http.FileServer(http.Dir("../")))
 	http.Handle("/", http.FileServer(http.FS(staticFiles)))
+	// networked games, see the online package for the messages
+	h := newHub(*grace, *absent)
+	http.Handle(online.Path, serveWebSocket(h))
+	// games being broadcast, watched with static/watch.html
+	http.Handle(online.BroadcastsPath, serveBroadcasts(h))
+	// games played over plain http, for scripts and other tools
+	http.Handle(apiPath, serveAPI(newAPIGames()))
 	go http.ListenAndServe(fmt.Sprintf(":%v", port), nil)
 	fmt.Printf("Server started at port %v\n", port)
 	<-done
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"sixDivides/bot"
+	"sixDivides/notation"
+	"sixDivides/online"
+	"sixDivides/rules"
+)
+
+var (
+	errNotInRoom   = errors.New("join a room first")
+	errNoRoom      = errors.New("there is no room with that code")
+	errNotYourTurn = errors.New("it is not your turn")
+	errSpectator   = errors.New("spectators can not make moves")
+	errNotStarted  = errors.New("the game has not started yet")
+	errStarted     = errors.New("the game has already started")
+	errBadToken    = errors.New("that player is not in this room")
+)
+
+// sendBuffer is how many messages can wait for a slow client before it is disconnected
+const sendBuffer = 64
+
+// client is one WebSocket connection
+type client struct {
+	// mu guards send, which is closed once the connection is finished with
+	mu   sync.Mutex
+	send chan online.ServerMessage
+	name string
+	room *room
+	// section is the corner the client sat in while the room was a lobby, or online.NoSection
+	section int
+	ready   bool
+	// player is the index of the player the client plays, or online.Spectator
+	player int
+	// publishing is the game the client is broadcasting, and watching the broadcast it is watching
+	publishing *broadcast
+	watching   *broadcast
+}
+
+// room is a game on the server, which starts as a lobby until the host
+// starts it. The room holds the only copy of the state that counts, the
+// clients state is only ever a copy of it.
+type room struct {
+	mu       sync.Mutex
+	hub      *hub
+	code     string
+	host     *client
+	width    int
+	height   int
+	sections [online.Sections]*client
+	started  bool
+	state    rules.State
+	record   notation.Record
+	sessions []*session
+	clients  map[*client]bool
+	// bot plays for any player that has been gone longer than the grace period, and
+	// botBusy is true while it is thinking
+	bot     bot.Bot
+	botBusy bool
+	// emptySince is when the last client left a started game
+	emptySince time.Time
+	// guests is the number of clients that have joined without a name, to name the next one
+	guests int
+}
+
+// hub is every room and broadcast on the server, by code
+type hub struct {
+	mu         sync.Mutex
+	rooms      map[string]*room
+	broadcasts map[string]*broadcast
+	// grace is how long a player who lost their connection has to come back
+	grace time.Duration
+	// absent is what happens to their turns after that, absentSkip or a bot kind
+	absent string
+}
+
+func newHub(grace time.Duration, absent string) *hub {
+	return &hub{rooms: map[string]*room{}, broadcasts: map[string]*broadcast{}, grace: grace, absent: absent}
+}
+
+// join adds the client to the room with the join code. Before the game starts
+// they sit in the first free section, after it they watch unless they have the
+// token of one of the players.
+func (h *hub) join(c *client, msg online.ClientMessage) error {
+	if c.room != nil {
+		return fmt.Errorf("already in room %v", c.room.code)
+	}
+	if c.publishing != nil || c.watching != nil {
+		return errors.New("already broadcasting or watching a broadcast")
+	}
+
+	h.mu.Lock()
+	r, ok := h.rooms[msg.Room]
+	if !ok {
+		h.mu.Unlock()
+		return errNoRoom
+	}
+	// lock the room before the hub is unlocked, so it can not be closed in between
+	r.mu.Lock()
+	h.mu.Unlock()
+	defer r.mu.Unlock()
+	if msg.Token != "" {
+		return r.rejoin(c, msg.Token)
+	}
+	return r.enter(c, msg.Name)
+}
+
+// enter adds the client to the room, the room must be locked
+func (r *room) enter(c *client, name string) error {
+	c.room = r
+	c.name = name
+	if c.name == "" {
+		r.guests++
+		c.name = fmt.Sprintf("Guest %v", r.guests)
+	}
+	c.section = online.NoSection
+	c.ready = false
+	c.player = online.Spectator
+	r.clients[c] = true
+
+	if !r.started {
+		for i, sat := range r.sections {
+			if sat == nil {
+				r.sections[i] = c
+				c.section = i
+				break
+			}
+		}
+		log.Printf("%v joined room %v in section %v", c.name, r.code, c.section)
+		r.broadcastLobby()
+		return nil
+	}
+
+	log.Printf("%v is watching room %v", c.name, r.code)
+	return r.sendGame(c)
+}
+
+// sendGame sends the game so far to the client, the room must be locked
+func (r *room) sendGame(c *client) error {
+	text, err := r.record.MarshalText()
+	if err != nil {
+		return err
+	}
+	msg := online.ServerMessage{Type: online.TypeJoined, Room: r.code, Player: c.player, Record: string(text)}
+	if c.player != online.Spectator {
+		msg.Token = r.sessions[c.player].token
+	}
+	c.deliver(msg)
+	return nil
+}
+
+// leave frees the clients section or player, so another client can take it over
+func (h *hub) leave(c *client) {
+	h.stopBroadcasts(c)
+	r := c.room
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	delete(r.clients, c)
+	if c.section != online.NoSection {
+		r.sections[c.section] = nil
+	}
+	if c.player != online.Spectator {
+		r.disconnected(r.sessions[c.player])
+	}
+	if r.host == c {
+		r.host = nextHost(r)
+	}
+	if !r.started {
+		r.broadcastLobby()
+	}
+	empty := len(r.clients) == 0
+	// a game being played is kept for the players to come back to
+	keep := empty && r.started && !r.state.GameOver
+	if keep {
+		r.emptySince = time.Now()
+		time.AfterFunc(abandonedAfter, func() { h.closeAbandoned(r) })
+	}
+	r.mu.Unlock()
+
+	if empty && !keep {
+		h.mu.Lock()
+		// the room may have been joined again while it was unlocked
+		r.mu.Lock()
+		if len(r.clients) == 0 && h.rooms[r.code] == r {
+			delete(h.rooms, r.code)
+			r.closeBot()
+			log.Printf("room %v closed", r.code)
+		}
+		r.mu.Unlock()
+		h.mu.Unlock()
+	}
+}
+
+// closeAbandoned closes the room if nobody has come back to it since the last client left
+func (h *hub) closeAbandoned(r *room) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.clients) == 0 && time.Since(r.emptySince) >= abandonedAfter && h.rooms[r.code] == r {
+		delete(h.rooms, r.code)
+		r.closeBot()
+		log.Printf("room %v closed, nobody came back", r.code)
+	}
+}
+
+// move checks the move against the rules, and sends it to everyone in the room when it is allowed
+func (r *room) move(c *client, text string) error {
+	m, err := notation.ParseMove(text)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.started {
+		return errNotStarted
+	}
+	if c.player == online.Spectator {
+		return errSpectator
+	}
+	if r.state.Turn != c.player {
+		return errNotYourTurn
+	}
+	if err := r.apply(m); err != nil {
+		return err
+	}
+	r.playAbsent()
+	return nil
+}
+
+// apply plays the move for the current player and sends it to everyone in the room, the room must be locked
+func (r *room) apply(m rules.Move) error {
+	player := r.state.Turn
+	n, result, err := rules.Apply(r.state, m)
+	if err != nil {
+		return err
+	}
+	m.Kind = result.Kind
+	text, err := notation.FormatMove(m)
+	if err != nil {
+		return err
+	}
+
+	r.state = n
+	r.record.Moves = append(r.record.Moves, m)
+	for other := range r.clients {
+		other.deliver(online.ServerMessage{Type: online.TypeMoved, Room: r.code, Player: player, Move: text})
+	}
+	return nil
+}
+
+// broadcastNotice tells everyone in the room about the player, the room must be locked
+func (r *room) broadcastNotice(player int, format string, args ...any) {
+	notice := fmt.Sprintf(format, args...)
+	log.Printf("room %v: %v", r.code, notice)
+	for c := range r.clients {
+		c.deliver(online.ServerMessage{Type: online.TypeNotice, Room: r.code, Player: player, Notice: notice})
+	}
+}
+
+// deliver queues the message for the client, without waiting on a slow connection
+func (c *client) deliver(msg online.ServerMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.send == nil {
+		return
+	}
+	select {
+	case c.send <- msg:
+	default:
+		log.Printf("dropping a client that is not keeping up")
+		close(c.send)
+		c.send = nil
+	}
+}
+
+// finish stops any more messages being queued, and lets the writer close the connection
+func (c *client) finish() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.send != nil {
+		close(c.send)
+		c.send = nil
+	}
+}
+
+// checkBoard makes sure a client can not create a board the rules can not play on
+func checkBoard(width int, height int) error {
+	return rules.CheckBoardSize(width, height)
+}
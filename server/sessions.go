@@ -0,0 +1,201 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"time"
+
+	"sixDivides/bot"
+	"sixDivides/online"
+	"sixDivides/rules"
+)
+
+// absentSkip ends the turns of a player who has been gone longer than the grace period
+const absentSkip = "skip"
+
+// abandonedAfter is how long a started game is kept once every client has left it
+const abandonedAfter = 30 * time.Minute
+
+// absentBotDelay is the pause before each action of a bot playing for an absent player,
+// so the others can follow what it does
+const absentBotDelay = 600 * time.Millisecond
+
+// session is a player of a started game. It outlives the players connection,
+// so they can come back to it with the token.
+type session struct {
+	// client is nil while the player is not connected
+	client *client
+	name   string
+	token  string
+	// left is when the player lost their connection
+	left time.Time
+	// absent is set once the grace period is over, and their turns are played for them
+	absent bool
+}
+
+// newToken makes a secret for a session, that can not be guessed by the other players
+func newToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand does not fail on the systems the server runs on
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// rejoin gives the client the player with the token back, the room must be locked
+func (r *room) rejoin(c *client, token string) error {
+	if !r.started {
+		return errNotStarted
+	}
+	player := -1
+	for i, s := range r.sessions {
+		if s.token == token {
+			player = i
+		}
+	}
+	if player == -1 {
+		return errBadToken
+	}
+
+	s := r.sessions[player]
+	if s.client != nil {
+		// the old connection has not noticed it is gone yet, so it is left to watch
+		s.client.player = online.Spectator
+	}
+	c.room = r
+	c.name = s.name
+	c.section = online.NoSection
+	c.player = player
+	r.clients[c] = true
+	wasAbsent := s.absent
+	s.client = c
+	s.absent = false
+
+	if err := r.sendGame(c); err != nil {
+		return err
+	}
+	if wasAbsent || !s.left.IsZero() {
+		r.broadcastNotice(player, "%v is back", s.name)
+	}
+	s.left = time.Time{}
+	// the game waits while nobody is connected, so it may be someone elses turn to play for
+	r.playAbsent()
+	return nil
+}
+
+// disconnected starts the grace period for a player who lost their connection, the room must be locked
+func (r *room) disconnected(s *session) {
+	s.client = nil
+	if r.state.GameOver {
+		return
+	}
+	s.left = time.Now()
+	player := r.playerOf(s)
+	r.broadcastNotice(player, "%v lost their connection", s.name)
+
+	left := s.left
+	time.AfterFunc(r.hub.grace, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		// they may have come back, and even left again, since
+		if s.client != nil || s.absent || !s.left.Equal(left) || r.state.GameOver {
+			return
+		}
+		s.absent = true
+		if r.hub.absent == absentSkip {
+			r.broadcastNotice(player, "%v did not come back, their turns are skipped", s.name)
+		} else {
+			r.broadcastNotice(player, "%v did not come back, a bot plays for them", s.name)
+		}
+		r.playAbsent()
+	})
+}
+
+// playerOf is the index of the sessions player
+func (r *room) playerOf(s *session) int {
+	for i, other := range r.sessions {
+		if other == s {
+			return i
+		}
+	}
+	return online.Spectator
+}
+
+// playAbsent plays the turns of absent players, the room must be locked. It
+// only does so while someone still in the game is connected, so an abandoned
+// game waits.
+func (r *room) playAbsent() {
+	skipped := 0
+	for !r.state.GameOver && !r.botBusy && r.sessions[r.state.Turn].absent && r.anyConnected() {
+		if r.hub.absent == absentSkip {
+			if skipped >= len(r.state.Players) {
+				// a whole round went by with nobody there to play, so wait for someone to come back
+				log.Printf("room %v: every player left in the game is absent", r.code)
+				return
+			}
+			if err := r.apply(rules.EndTurnMove); err != nil {
+				log.Printf("room %v: could not skip a turn: %v", r.code, err)
+				return
+			}
+			skipped++
+			continue
+		}
+
+		if r.bot == nil {
+			b, err := bot.New(r.hub.absent, time.Now().UnixNano())
+			if err != nil {
+				log.Printf("room %v: %v", r.code, err)
+				return
+			}
+			r.bot = b
+		}
+		r.botBusy = true
+		go r.playBot(r.bot, r.state.Clone(), len(r.record.Moves))
+		return
+	}
+}
+
+// playBot works out the bots next action without holding the room, and plays
+// it if the game has not moved on in the meantime
+func (r *room) playBot(b bot.Bot, s rules.State, moves int) {
+	time.Sleep(absentBotDelay)
+	m := b.NextMove(s)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.botBusy = false
+	if len(r.record.Moves) != moves || !r.sessions[s.Turn].absent {
+		// they came back while the bot was thinking
+		r.playAbsent()
+		return
+	}
+	if err := r.apply(m); err != nil {
+		log.Printf("room %v: the bot tried %v: %v", r.code, m, err)
+		if err := r.apply(rules.EndTurnMove); err != nil {
+			return
+		}
+	}
+	r.playAbsent()
+}
+
+// closeBot stops the rooms bot when the room closes, the room must be locked
+func (r *room) closeBot() {
+	if r.bot != nil {
+		// an external bot can take a while to quit
+		go bot.Close(r.bot)
+		r.bot = nil
+	}
+}
+
+// anyConnected is true while at least one player who is still in the game is
+// connected to the room, the eliminated ones only watch
+func (r *room) anyConnected() bool {
+	for i, s := range r.sessions {
+		if s.client != nil && i < len(r.state.Players) && !r.state.Players[i].Eliminated {
+			return true
+		}
+	}
+	return false
+}
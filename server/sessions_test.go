@@ -0,0 +1,295 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"sixDivides/notation"
+	"sixDivides/online"
+	"sixDivides/rules"
+)
+
+// testGrace is short, so the tests do not wait long for a player to count as absent
+const testGrace = 20 * time.Millisecond
+
+// newTestClient is a client without a connection, its messages stay queued for the test to read
+func newTestClient() *client {
+	return &client{send: make(chan online.ServerMessage, 256), section: online.NoSection, player: online.Spectator}
+}
+
+// startTestRoom starts a 8x8 game with a client for each name, the first the host,
+// so the clients are the players in the order of the names
+func startTestRoom(t *testing.T, h *hub, names ...string) (*room, []*client) {
+	t.Helper()
+	clients := make([]*client, len(names))
+	for i, name := range names {
+		clients[i] = newTestClient()
+		var err error
+		if i == 0 {
+			err = h.create(clients[i], online.ClientMessage{Type: online.TypeCreate, Width: 7, Height: 7, Name: name})
+		} else {
+			err = h.join(clients[i], online.ClientMessage{Type: online.TypeJoin, Room: clients[0].room.code, Name: name})
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := clients[0].room
+	for _, c := range clients[1:] {
+		if err := r.setReady(c, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.start(clients[0]); err != nil {
+		t.Fatal(err)
+	}
+	return r, clients
+}
+
+// waitFor fails the test if the room does not get to the condition soon, the room is locked while checking it
+func waitFor(t *testing.T, r *room, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		r.mu.Lock()
+		ok := cond()
+		r.mu.Unlock()
+		if ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("gave up waiting for %v", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// received is every message queued for the client so far
+func received(c *client) []online.ServerMessage {
+	var msgs []online.ServerMessage
+	for {
+		select {
+		case msg := <-c.send:
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+}
+
+// noticesIn is the text of the notices in the messages
+func noticesIn(msgs []online.ServerMessage) []string {
+	var notices []string
+	for _, msg := range msgs {
+		if msg.Type == online.TypeNotice {
+			notices = append(notices, msg.Notice)
+		}
+	}
+	return notices
+}
+
+// tokenOf is the token of the player, as the room has it
+func tokenOf(r *room, player int) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sessions[player].token
+}
+
+func TestRejoin(t *testing.T) {
+	h := newHub(testGrace, absentSkip)
+	r, clients := startTestRoom(t, h, "a", "b")
+	received(clients[0])
+
+	// b comes back before the grace period is over
+	h.leave(clients[1])
+	back := newTestClient()
+	if err := h.join(back, online.ClientMessage{Type: online.TypeJoin, Room: r.code, Token: tokenOf(r, 1)}); err != nil {
+		t.Fatal(err)
+	}
+	joined := received(back)
+	if len(joined) == 0 || joined[0].Type != online.TypeJoined || joined[0].Player != 1 || joined[0].Token != tokenOf(r, 1) {
+		t.Fatalf("the rejoined client got %+v, want the game as player 1 with their token", joined)
+	}
+	if back.player != 1 || back.name != "b" {
+		t.Errorf("the rejoined client is %q player %v, want b player 1", back.name, back.player)
+	}
+
+	// the grace timer of the first connection finds them back, and does nothing
+	time.Sleep(3 * testGrace)
+	r.mu.Lock()
+	s := r.sessions[1]
+	if s.client != back || s.absent || !s.left.IsZero() {
+		t.Errorf("the session has client %p absent %v left %v, want the rejoined client %p", s.client, s.absent, s.left, back)
+	}
+	r.mu.Unlock()
+	notices := noticesIn(received(clients[0]))
+	if len(notices) != 2 || !strings.Contains(notices[0], "lost their connection") || !strings.Contains(notices[1], "is back") {
+		t.Errorf("notices = %q, want the lost connection and b being back", notices)
+	}
+
+	if err := h.join(newTestClient(), online.ClientMessage{Type: online.TypeJoin, Room: r.code, Token: "not a token"}); err != errBadToken {
+		t.Errorf("joining with a bad token = %v, want %v", err, errBadToken)
+	}
+
+	// this time b stays away long enough to be absent, and comes back after
+	h.leave(back)
+	waitFor(t, r, "b to be absent", func() bool { return r.sessions[1].absent })
+	if err := h.join(newTestClient(), online.ClientMessage{Type: online.TypeJoin, Room: r.code, Token: tokenOf(r, 1)}); err != nil {
+		t.Fatal(err)
+	}
+	r.mu.Lock()
+	if r.sessions[1].absent || r.sessions[1].client == nil {
+		t.Errorf("b is still absent after coming back")
+	}
+	r.mu.Unlock()
+	notices = noticesIn(received(clients[0]))
+	if len(notices) != 3 || !strings.Contains(notices[1], "did not come back") || !strings.Contains(notices[2], "is back") {
+		t.Errorf("notices = %q, want b losing their connection, being absent and coming back", notices)
+	}
+}
+
+// TestAbsentSkipped checks an absent players turn is skipped once each round, not more
+func TestAbsentSkipped(t *testing.T) {
+	h := newHub(testGrace, absentSkip)
+	r, clients := startTestRoom(t, h, "a", "b", "c")
+	h.leave(clients[1])
+	waitFor(t, r, "b to be absent", func() bool { return r.sessions[1].absent })
+	received(clients[0])
+
+	const rounds = 3
+	for i := 0; i < rounds; i++ {
+		if err := r.move(clients[0], notation.PassToken); err != nil {
+			t.Fatal(err)
+		}
+		if r.state.Turn != 2 {
+			t.Fatalf("round %v: after a passed it is player %v's turn, want b skipped to player 2", i, r.state.Turn)
+		}
+		if err := r.move(clients[2], notation.PassToken); err != nil {
+			t.Fatal(err)
+		}
+		if r.state.Turn != 0 {
+			t.Fatalf("round %v: after c passed it is player %v's turn, want 0", i, r.state.Turn)
+		}
+	}
+
+	played := make([]int, 3)
+	for _, msg := range received(clients[0]) {
+		if msg.Type == online.TypeMoved {
+			if msg.Move != notation.PassToken {
+				t.Errorf("player %v played %v, want only passes", msg.Player, msg.Move)
+			}
+			played[msg.Player]++
+		}
+	}
+	for player, n := range played {
+		if n != rounds {
+			t.Errorf("player %v had %v turns in %v rounds", player, n, rounds)
+		}
+	}
+	if len(r.record.Moves) != 3*rounds {
+		t.Errorf("the record has %v moves, want %v", len(r.record.Moves), 3*rounds)
+	}
+}
+
+// TestAbsentWholeRound checks skipping stops once a whole round went by with
+// only absent players able to play, rather than skipping forever
+func TestAbsentWholeRound(t *testing.T) {
+	h := newHub(testGrace, absentSkip)
+	r, clients := startTestRoom(t, h, "a", "b")
+	h.leave(clients[1])
+	waitFor(t, r, "b to be absent", func() bool { return r.sessions[1].absent })
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// a is still connected, but only has a soldier, which makes no actions
+	a := &r.state.Players[0]
+	a.Pieces = []rules.Piece{{Color: a.Color, Value: 2, PlayerIndex: 0, Position: a.Pieces[0].Position}}
+	a.Actions = 0
+	r.state.Turn = 1
+	r.state.Players[1].Actions = rules.ActionsFor(r.state.Players[1])
+	r.state.SyncBoard()
+
+	r.playAbsent()
+	if len(r.record.Moves) != len(r.state.Players) {
+		t.Errorf("%v turns were skipped, want a round of %v", len(r.record.Moves), len(r.state.Players))
+	}
+	if r.state.GameOver || r.state.Turn != 1 {
+		t.Errorf("game over = %v on turn %v, want the game waiting on b", r.state.GameOver, r.state.Turn)
+	}
+}
+
+// TestAbsentOnlyEliminatedConnected checks the game waits when the only player
+// connected has been eliminated, and is just watching
+func TestAbsentOnlyEliminatedConnected(t *testing.T) {
+	h := newHub(testGrace, absentSkip)
+	r, clients := startTestRoom(t, h, "a", "b", "c")
+	h.leave(clients[1])
+	h.leave(clients[2])
+	waitFor(t, r, "b and c to be absent", func() bool { return r.sessions[1].absent && r.sessions[2].absent })
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state.Players[0].Pieces = nil
+	r.state.Players[0].Eliminated = true
+	r.state.Turn = 1
+	r.state.Players[1].Actions = rules.ActionsFor(r.state.Players[1])
+	r.state.SyncBoard()
+
+	r.playAbsent()
+	if len(r.record.Moves) != 0 || r.state.Turn != 1 {
+		t.Errorf("%v turns were skipped and it is player %v's turn, want none skipped with nobody playing", len(r.record.Moves), r.state.Turn)
+	}
+}
+
+// TestAbsentBot checks the bot plays for an absent player, and that its move is
+// thrown away when the player comes back while the bot is thinking
+func TestAbsentBot(t *testing.T) {
+	t.Run("plays", func(t *testing.T) {
+		h := newHub(testGrace, "random")
+		r, clients := startTestRoom(t, h, "a", "b")
+		h.leave(clients[1])
+		waitFor(t, r, "b to be absent", func() bool { return r.sessions[1].absent })
+		if err := r.move(clients[0], notation.PassToken); err != nil {
+			t.Fatal(err)
+		}
+		waitFor(t, r, "the bot to play for b", func() bool { return len(r.record.Moves) > 1 })
+		for _, msg := range received(clients[0]) {
+			if msg.Type == online.TypeMoved && msg.Move != notation.PassToken && msg.Player != 1 {
+				t.Errorf("player %v played %v, want the bot playing for player 1", msg.Player, msg.Move)
+			}
+		}
+		h.leave(clients[0])
+	})
+
+	t.Run("rejoin while thinking", func(t *testing.T) {
+		h := newHub(testGrace, "random")
+		r, clients := startTestRoom(t, h, "a", "b")
+		h.leave(clients[1])
+		waitFor(t, r, "b to be absent", func() bool { return r.sessions[1].absent })
+		if err := r.move(clients[0], notation.PassToken); err != nil {
+			t.Fatal(err)
+		}
+		r.mu.Lock()
+		busy := r.botBusy
+		r.mu.Unlock()
+		if !busy {
+			t.Fatal("the bot is not playing for b")
+		}
+
+		back := newTestClient()
+		if err := h.join(back, online.ClientMessage{Type: online.TypeJoin, Room: r.code, Token: tokenOf(r, 1)}); err != nil {
+			t.Fatal(err)
+		}
+		waitFor(t, r, "the bot to finish thinking", func() bool { return !r.botBusy })
+		r.mu.Lock()
+		moves, turn := len(r.record.Moves), r.state.Turn
+		r.mu.Unlock()
+		if moves != 1 || turn != 1 {
+			t.Fatalf("the record has %v moves on turn %v, want the bots move thrown away and b to play", moves, turn)
+		}
+		if err := r.move(back, notation.PassToken); err != nil {
+			t.Errorf("b could not play after coming back: %v", err)
+		}
+	})
+}
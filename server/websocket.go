@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+	"sixDivides/online"
+)
+
+// writeTimeout is how long a message can take to send before the connection is given up on
+const writeTimeout = 10 * time.Second
+
+// serveWebSocket handles a client connection for as long as it is open
+func serveWebSocket(h *hub) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			log.Printf("could not accept websocket: %v", err)
+			return
+		}
+		defer conn.CloseNow()
+		conn.SetReadLimit(online.MaxMessageSize)
+
+		c := &client{send: make(chan online.ServerMessage, sendBuffer), section: online.NoSection, player: online.Spectator}
+		go c.writeLoop(r.Context(), conn, c.send)
+		defer c.finish()
+		defer h.leave(c)
+
+		for {
+			var msg online.ClientMessage
+			if err := wsjson.Read(r.Context(), conn, &msg); err != nil {
+				if websocket.CloseStatus(err) != websocket.StatusNormalClosure && !errors.Is(err, context.Canceled) {
+					log.Printf("client disconnected: %v", err)
+				}
+				return
+			}
+			if err := handleMessage(h, c, msg); err != nil {
+				c.deliver(online.ServerMessage{Type: online.TypeError, Player: online.Spectator, Error: err.Error()})
+			}
+		}
+	}
+}
+
+func handleMessage(h *hub, c *client, msg online.ClientMessage) error {
+	switch msg.Type {
+	case online.TypeCreate:
+		return h.create(c, msg)
+	case online.TypeJoin:
+		return h.join(c, msg)
+	case online.TypeBroadcast:
+		if c.room != nil {
+			return fmt.Errorf("already in room %v", c.room.code)
+		}
+		return h.publish(c, msg)
+	case online.TypeWatch:
+		return h.watch(c, msg.Room)
+	}
+
+	if c.room == nil {
+		return errNotInRoom
+	}
+	switch msg.Type {
+	case online.TypeSeat:
+		return c.room.seat(c, msg.Section)
+	case online.TypeReady:
+		return c.room.setReady(c, msg.Ready)
+	case online.TypeStart:
+		return c.room.start(c)
+	case online.TypeMove:
+		return c.room.move(c, msg.Move)
+	}
+	return errors.New("unknown message type " + msg.Type)
+}
+
+// writeLoop sends the queued messages in order, and closes the connection once the queue is closed
+func (c *client) writeLoop(ctx context.Context, conn *websocket.Conn, send <-chan online.ServerMessage) {
+	for msg := range send {
+		writeCtx, cancel := context.WithTimeout(ctx, writeTimeout)
+		err := wsjson.Write(writeCtx, conn, msg)
+		cancel()
+		if err != nil {
+			conn.CloseNow()
+			return
+		}
+	}
+	conn.Close(websocket.StatusNormalClosure, "")
+}